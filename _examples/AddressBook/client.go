@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	addressbook "github.com/idleroamer/goqface/_examples/AddressBook/Examples/AddressBook"
+	goqface "github.com/idleroamer/goqface/objectManager"
 
 	"github.com/godbus/dbus/v5"
 )
@@ -47,10 +48,9 @@ func main() {
 	defer conn.Close()
 
 	addressBookSignalHandler := &AddressBookProxySignals{}
-	proxy := &addressbook.AddressBookProxy{Conn: conn}
-	proxy.Init()
-	proxy.ConnectToRemoteObject("goqface.addressbook")
+	proxy := addressbook.NewAddressBookProxy(conn, goqface.WithServiceName("goqface.addressbook"))
 	proxy.AddContactsChangedObserver(addressBookSignalHandler)
+	proxy.ConnectToRemoteObject()
 	proxy.SetContacts([]addressbook.Contact{addressbook.Contact{1, "JohnDoe", "TelNummer", 2}, addressbook.Contact{2, "MAxMusterman", "Handy", 234}})
 
 	c := make(chan *dbus.Signal, 10)
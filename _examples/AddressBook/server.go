@@ -4,12 +4,13 @@ package main
 //go:generate gofmt -w Examples
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"os"
 	"strconv"
 
 	addressbook "github.com/idleroamer/goqface/_examples/AddressBook/Examples/AddressBook"
+	goqface "github.com/idleroamer/goqface/objectManager"
 
 	"github.com/godbus/dbus/v5"
 )
@@ -47,41 +48,30 @@ func (addressbookInterface *AddressBookImpl) SelectContact(contactId int) *dbus.
 		}
 	}
 	if !found {
-		return dbus.MakeFailedError(dbus.ErrMsgInvalidArg)
+		return addressbook.ErrNotFound.New("no contact %d to select", contactId)
 	}
 	return nil
 }
 
 func (addressbookInterface *AddressBookImpl) DeleteContact(contactId int) (bool, *dbus.Error) {
-	found := false
-	i := 0
-	tmpContacts := addressbookInterface.Contacts()
-	for _, entry := range tmpContacts {
+	// Contacts returns a copy to be modified
+	contacts := addressbookInterface.Contacts()
+	for i, entry := range contacts {
 		if entry.Idx == contactId {
-			toBeDeletedContact := entry
-			addressbookInterface.ContactDeleted(toBeDeletedContact)
+			addressbookInterface.SetContacts(append(contacts[:i], contacts[i+1:]...))
+			addressbookInterface.ContactDeleted(entry)
 			fmt.Printf("DeleteContact: %d", contactId)
-			found = true
-		} else {
-			tmpContacts[i] = entry
-			i++
+			return true, nil
 		}
 	}
-	for j := i; j < len(tmpContacts); j++ {
-		fmt.Printf("newContactCreated: %v", len(addressbookInterface.Contacts()))
-		tmpContacts[j] = addressbook.Contact{}
-	}
-	if found {
-		addressbookInterface.SetContacts(tmpContacts)
-	} else {
-		return true, dbus.MakeFailedError(dbus.ErrMsgInvalidArg)
-	}
-	return true, nil
+	return false, addressbook.ErrNotFound.New("no contact %d to delete", contactId)
 }
 
 func (addressbookInterface *AddressBookImpl) UpdateContact(contactId int, contact addressbook.Contact) *dbus.Error {
-	if contactId >= 0 && contactId < len(addressbookInterface.Contacts()) {
-		addressbookInterface.Contacts()[contactId] = contact
+	contacts := addressbookInterface.Contacts()
+	if contactId >= 0 && contactId < len(contacts) {
+		contacts[contactId] = contact
+		addressbookInterface.SetContacts(contacts)
 		fmt.Printf("UpdateContact: %v", contact)
 	} else {
 		addressbookInterface.ContactUpdateFailed(addressbook.Other)
@@ -89,54 +79,42 @@ func (addressbookInterface *AddressBookImpl) UpdateContact(contactId int, contac
 	return nil
 }
 
-func (addressbookInterface *AddressBookImpl) CurrentContactAboutToBeSet(value addressbook.Contact) error {
-	return errors.New("No way")
-}
-
-func (addressbookInterface *AddressBookImpl) IsLoadedAboutToBeSet(isLoaded bool) error {
-	return nil
-}
-
-func (addressbookInterface *AddressBookImpl) ContactsAboutToBeSet(contacts []addressbook.Contact) error {
-	return nil
-}
-
-func (addressbookInterface *AddressBookImpl) IntValuesAboutToBeSet(intValues []int) error {
-	return nil
-}
-
-func (addressbookInterface *AddressBookImpl) NestedAboutToBeSet(nested addressbook.Nested) error {
-	return nil
+// ValidateCurrentContact rejects remote writes of contacts which are not part of the address book
+func (addressbookInterface *AddressBookImpl) ValidateCurrentContact(caller goqface.Caller, value addressbook.Contact) error {
+	for _, contact := range addressbookInterface.Contacts() {
+		if contact.Idx == value.Idx {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s selected unknown contact %d", goqface.ErrInvalidArgs, caller.Sender, value.Idx)
 }
 
 func main() {
 	addressbookServiceName := "goqface.addressbook"
 
-	conn, err := dbus.SessionBus()
+	conn, err := dbus.SessionBusPrivate()
 	if err != nil {
 		panic(err)
 	}
-	defer conn.Close()
-
-	reply, err := conn.RequestName(addressbookServiceName, dbus.NameFlagDoNotQueue)
-	if err != nil {
+	if err = conn.Auth(nil); err != nil {
 		panic(err)
 	}
-	if reply != dbus.RequestNameReplyPrimaryOwner {
-		fmt.Fprintln(os.Stderr, "name already taken")
-		os.Exit(1)
+	if err = conn.Hello(); err != nil {
+		panic(err)
 	}
 
-	addressbookAdapter := &addressbook.AddressBookAdapter{Conn: conn}
-	addressBookImpl := &AddressBookImpl{&addressbook.AddressBookBase{}}
-
-	addressbookAdapter.Init(addressBookImpl)
-	addressbookAdapter.Export()
+	server := goqface.NewServer(conn)
+	addressbookAdapter := addressbook.NewAddressBookAdapter(conn, &AddressBookImpl{addressbook.NewAddressBookBase()},
+		goqface.WithServiceName(addressbookServiceName))
+	if err := server.Export(addressbookAdapter); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
 	fmt.Println("Listening on serviceName: " + addressbookServiceName + " objectPath: " + string(addressbookAdapter.ObjectPath()) + "...")
 
-	c := make(chan *dbus.Signal)
-	conn.Signal(c)
-	for _ = range c {
+	if err := server.Run(context.Background()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
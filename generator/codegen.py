@@ -7,46 +7,361 @@ import qface
 import subprocess
 import sys
 import os
+import re
 
 logger = logging.getLogger(__name__)
 
-parser = argparse.ArgumentParser(description='Generates bindings for godbus based on the qface IDL.')
+parser = argparse.ArgumentParser(description='Generates bindings for godbus based on the qface IDL, '
+                                             'run "codegen.py compat --help" to compare revisions of qface modules instead.')
 parser.add_argument('--input', dest='input', type=str, required=True, nargs='+',
                     help='qface input interfaces or path to them, folders will be walked')
 parser.add_argument('--output', dest='output', type=str, required=False, default='.',
                     help='path to place the generated code relative to go module base path, default value is current directory')
 parser.add_argument('--dependency', dest='dependency', type=str, required=False, nargs='+', default=[],
                     help='path to dependency .qface files, leave empty if there is no interdependency')
-args = parser.parse_args()
 
 yaml_annotate = ".go.annotate"
 
 
-def go_type(self: object) -> object:
-    if self.type.is_primitive:
-        if self.type.name == 'real':
-            return 'float64'
-        return self.type
-    elif self.type.is_void:
-        return "what"
-    elif self.type.is_list:
-        return '[]{0}'.format(go_type(self.type.nested))
-    elif self.type.is_map:
-        return 'map[string]{0}'.format(go_type(self.type.nested))
+go_primitives = {
+    'bool': 'bool',
+    'int': 'int',
+    'real': 'float64',
+    'string': 'string',
+    'var': 'dbus.Variant',
+}
+
+
+def key_types(self):
+    """Returns the key types of the maps of self, parameters are keyed by the annotation of their operation or signal
+    mapping parameter names to keys as qface has no annotations of parameters"""
+    if isinstance(self, qface.idl.domain.Parameter):
+        keys = getattr(self.operation, 'tags', {}).get('dbus.key')
+        keys = keys.get(self.name, []) if isinstance(keys, dict) else []
     else:
-        split = self.type.name.split(".")
-        if len(split) > 1:
-            if self.type.name.rpartition('.')[0] == self.module.name:
-                return split[-1]
-            return ''.join(split[:-1]) + '.' + split[-1]
-        else:
-            return split[0]
+        keys = getattr(self, 'tags', {}).get('dbus.key', [])
+        if isinstance(keys, dict):
+            keys = []
+    return keys if isinstance(keys, list) else [keys]
+
+
+def go_type_of(type, module, keys):
+    """Maps a qface type used in module to Go, keys are the key types of the maps from the outermost one on"""
+    if type.is_void:
+        raise ValueError('void is no value type')
+    elif type.is_primitive:
+        if type.name not in go_primitives:
+            raise ValueError('type {0} is not supported'.format(type.name))
+        return go_primitives[type.name]
+    elif type.is_list:
+        return '[]{0}'.format(go_type_of(type.nested, module, keys))
+    elif type.is_map:
+        key = str(keys[0]) if keys else 'string'
+        if key not in go_primitives or key == 'var':
+            raise ValueError('map key {0} is none of bool, int, real, string'.format(key))
+        return 'map[{0}]{1}'.format(go_primitives[key], go_type_of(type.nested, module, keys[1:]))
+    elif type.is_struct or type.is_enum or type.is_flag:
+        reference = type.reference
+        if reference is None:
+            raise ValueError('unknown type {0}, pass the module declaring it by --dependency'.format(type.name))
+        if reference.module is module:
+            return reference.name
+        return ''.join(reference.module.name_parts) + '.' + reference.name
+    raise ValueError('type {0} can not be mapped to Go'.format(type.name))
+
+
+def go_type(self):
+    try:
+        return go_type_of(self.type, self.module, key_types(self))
+    except ValueError as exc:
+        raise ValueError('Invalid type of {0}: {1}'.format(self.qualified_name, exc))
+
+
+def go_equal_of(type, module, keys, a, b, depth=0):
+    """Returns a Go expression comparing a and b of type without reflection"""
+    if type.is_list or type.is_map:
+        x, y = 'x{0}'.format(depth), 'y{0}'.format(depth)
+        nested_keys = keys if type.is_list else keys[1:]
+        return 'goqface.Equal{0}({1}, {2}, func({3}, {4} {5}) bool {{ return {6} }})'.format(
+            'Slices' if type.is_list else 'Maps', a, b, x, y, go_type_of(type.nested, module, nested_keys),
+            go_equal_of(type.nested, module, nested_keys, x, y, depth + 1))
+    elif type.is_struct:
+        return '{0}.Equal({1})'.format(a, b)
+    elif type.is_primitive and type.name == 'var':
+        return 'goqface.EqualVariants({0}, {1})'.format(a, b)
+    return '{0} == {1}'.format(a, b)
+
+
+def go_clone_of(type, module, keys, x, depth=0):
+    """Returns a Go expression deeply copying x of type"""
+    if type.is_list or type.is_map:
+        v = 'v{0}'.format(depth)
+        nested_keys = keys if type.is_list else keys[1:]
+        clone = 'nil'
+        if type.nested.is_list or type.nested.is_map or type.nested.is_struct:
+            clone = 'func({0} {1}) {1} {{ return {2} }}'.format(
+                v, go_type_of(type.nested, module, nested_keys), go_clone_of(type.nested, module, nested_keys, v, depth + 1))
+        return 'goqface.Clone{0}({1}, {2})'.format('Slice' if type.is_list else 'Map', x, clone)
+    elif type.is_struct:
+        return '{0}.Clone()'.format(x)
+    return x
+
+
+def go_equal(self, a, b):
+    return go_equal_of(self.type, self.module, key_types(self), a, b)
+
+
+def go_clone(self, x):
+    return go_clone_of(self.type, self.module, key_types(self), x)
+
+
+def doc(self):
+    comment = self.comment or ''
+    lines = []
+    for line in comment.strip().splitlines():
+        line = line.strip()
+        if line.startswith('/**'):
+            line = line[3:]
+        if line.endswith('*/'):
+            line = line[:-2]
+        line = line.strip()
+        if line.startswith('*'):
+            line = line[1:]
+        lines.append(line.strip())
+    while lines and not lines[0]:
+        lines.pop(0)
+    while lines and not lines[-1]:
+        lines.pop()
+    return '\n'.join(lines)
+
+
+def deprecation(self):
+    """Returns the note of @deprecated, either the given text or a default one if annotated by true, empty if not deprecated"""
+    note = getattr(self, 'tags', {}).get('deprecated', False)
+    if note is True:
+        return '{0} may be removed by the next major version of {1}.'.format(self.name, self.module.name)
+    elif note is False or note is None:
+        return ''
+    elif not isinstance(note, str) or not note.strip():
+        raise ValueError('Invalid deprecated of {0}: {1}, expected true or a note'.format(self.qualified_name, note))
+    return ' '.join(note.split())
+
+
+def is_deprecated(self):
+    return bool(deprecation(self))
+
+
+def go_deprecation(self):
+    return '// Deprecated: ' + deprecation(self) if deprecation(self) else ''
+
+
+def go_doc(self):
+    lines = ['// ' + line if line else '//' for line in doc(self).splitlines()]
+    if deprecation(self):
+        lines += ['//', go_deprecation(self)] if lines else [go_deprecation(self)]
+    return '\n'.join(lines)
+
+
+def doc_literal(self):
+    return json.dumps(doc(self), ensure_ascii=False)
+
+
+def go_value(type, value, module, keys=()):
+    if type.is_bool:
+        if not isinstance(value, bool):
+            raise ValueError('Default value {0} is not a bool'.format(value))
+        return 'true' if value else 'false'
+    elif type.is_int or type.is_real:
+        if isinstance(value, bool) or not isinstance(value, (int, float)) or (type.is_int and not isinstance(value, int)):
+            raise ValueError('Default value {0} is not a {1}'.format(value, type.name))
+        return str(value)
+    elif type.is_string:
+        return json.dumps(str(value), ensure_ascii=False)
+    elif type.is_list:
+        if not isinstance(value, list):
+            raise ValueError('Default value {0} is not a list'.format(value))
+        return '{0}{{{1}}}'.format(go_type_of(type, module, keys), ', '.join(go_value(type.nested, v, module, keys) for v in value))
+    elif type.is_map:
+        if not isinstance(value, dict):
+            raise ValueError('Default value {0} is not a map'.format(value))
+        key = str(keys[0]) if keys else 'string'
+        return '{0}{{{1}}}'.format(go_type_of(type, module, keys), ', '.join(
+            '{0}: {1}'.format(go_key_value(key, k), go_value(type.nested, v, module, keys[1:])) for k, v in value.items()))
+    elif type.is_enum or type.is_flag:
+        qualifier = go_type_of(type, module, keys).rpartition('.')[0]
+        for member in type.reference.members:
+            if member.name == value or (not isinstance(value, str) and member.value == value):
+                return qualifier + '.' + member.unique_name if qualifier else member.unique_name
+        raise ValueError('Default value {0} is not a member of {1}'.format(value, type.name))
+    elif type.is_struct:
+        if not isinstance(value, dict):
+            raise ValueError('Default value {0} is not a struct'.format(value))
+        fields = {field.name: field for field in type.reference.fields}
+        for name in value:
+            if name not in fields:
+                raise ValueError('Default value {0} has no field {1} in {2}'.format(value, name, type.name))
+        values = []
+        for field in type.reference.fields:
+            if field.name in value:
+                values.append('{0}: {1}'.format(cap_name(field), go_value(field.type, value[field.name], module, key_types(field))))
+            elif go_default(field, module):
+                values.append('{0}: {1}'.format(cap_name(field), go_default(field, module)))
+        return '{0}{{{1}}}'.format(go_type_of(type, module, keys), ', '.join(values))
+    raise ValueError('No default value supported for type {0}'.format(type.name))
+
+
+def go_key_value(key, value):
+    if key == 'string':
+        return json.dumps(str(value), ensure_ascii=False)
+    try:
+        if key == 'bool':
+            return {'true': 'true', 'false': 'false'}[str(value).lower()]
+        elif key == 'int':
+            return str(int(str(value)))
+        return str(float(str(value)))
+    except (KeyError, ValueError):
+        raise ValueError('Default key {0} is not a {1}'.format(value, key))
+
+
+def json_name(self):
+    name = self.tags.get('json.name', self.name)
+    if not isinstance(name, str) or not re.match(r'^[A-Za-z0-9_.$@-]+$', name):
+        raise ValueError('Invalid json.name of {0}: {1}'.format(self.qualified_name, name))
+    return name
+
+
+def has_defaults(struct):
+    for field in struct.fields:
+        if go_default(field):
+            return True
+    return False
+
+
+def go_default(self, module=None):
+    """Returns the Go literal of the default of self to be used in module, the module of self by default"""
+    module = module or self.module
+    if 'default' in self.tags:
+        try:
+            return go_value(self.type, self.tags['default'], module, key_types(self))
+        except ValueError as exc:
+            raise ValueError('Invalid default of {0}: {1}'.format(self.qualified_name, exc))
+    elif self.type.is_struct and has_defaults(self.type.reference):
+        qualifier, _, name = go_type_of(self.type, module, []).rpartition('.')
+        return '{0}New{1}()'.format(qualifier + '.' if qualifier else '', name)
+    return ''
+
+
+emit_types = {
+    'true': 'prop.EmitTrue',
+    'false': 'prop.EmitFalse',
+    'invalidates': 'prop.EmitInvalidates',
+    'const': 'prop.EmitConst',
+}
+
+
+def go_emits(self):
+    value = str(self.tags.get('dbus.emits', 'true')).lower()
+    if value not in emit_types:
+        raise ValueError('Invalid dbus.emits of {0}: {1}, expected one of {2}'.format(
+            self.qualified_name, value, ', '.join(emit_types)))
+    return emit_types[value]
+
+
+access_subjects = ('uid', 'gid', 'user', 'group')
+
+
+def access_rules(self):
+    rules = self.tags.get('access', self.interface.tags.get('access'))
+    if rules is None:
+        return []
+    if not isinstance(rules, list):
+        rules = [rules]
+    for rule in rules:
+        subject, _, value = str(rule).partition('=')
+        if subject.strip() not in access_subjects or not value.strip():
+            raise ValueError('Invalid access of {0}: {1}, expected one of {2} followed by =value'.format(
+                self.qualified_name, rule, ', '.join(access_subjects)))
+    return [str(rule).replace(' ', '') for rule in rules]
+
+
+def go_access(self):
+    return '[]string{{{0}}}'.format(', '.join(json.dumps(rule) for rule in access_rules(self)))
+
+
+def error_names(self):
+    names = self.tags.get('errors', [])
+    if not isinstance(names, list):
+        names = [names]
+    for name in names:
+        if not isinstance(name, str) or not re.match(r'^[A-Za-z_][A-Za-z0-9_]*$', name):
+            raise ValueError('Invalid errors of {0}: {1} is no valid D-Bus error name element'.format(self.qualified_name, name))
+    return [name[0].upper() + name[1:] for name in names]
+
+
+def module_error_names(self):
+    return sorted({name for interface in self.interfaces for operation in interface.operations for name in error_names(operation)})
+
+
+def ready_default(self):
+    return 'true' if self.tags.get('ready') else 'false'
 
 
 def has_return_value(self):
     return not self.type.name == 'void'
 
 
+go_keywords = ('break', 'case', 'chan', 'const', 'continue', 'default', 'defer', 'else', 'fallthrough', 'for', 'func', 'go', 'goto',
+               'if', 'import', 'interface', 'map', 'package', 'range', 'return', 'select', 'struct', 'switch', 'type', 'var')
+
+# identifiers used by generated operations and packages imported along with them, parameters and named results must not shadow them
+reserved_operation_names = ('c', 'r', 'err', 'caller', 'dbusSender', 'errorDomain', 'nil', 'invalid', 'impl', 'ok',
+                         'context', 'dbus', 'errors', 'goqface', 'introspect', 'log', 'prop', 'reflect', 'strings', 'sync', 'time')
+
+
+def out_fields(self):
+    """Returns the fields of the struct returned by an operation annotated by @dbus.out, which become its named results"""
+    if not self.tags.get('dbus.out'):
+        return []
+    if not self.type.is_struct or self.type.reference.module is not self.module:
+        raise ValueError('Invalid dbus.out of {0}: {1} is no struct of module {2}'.format(
+            self.qualified_name, self.type.name, self.module.name))
+    parameters = {parameter.name for parameter in self.parameters}
+    for field in self.type.reference.fields:
+        if field.name in parameters:
+            reason = 'clashes with a parameter'
+        elif field.name in go_keywords:
+            reason = 'is a Go keyword'
+        elif field.name in reserved_operation_names:
+            reason = 'shadows an identifier used by the generated code'
+        else:
+            continue
+        raise ValueError('Invalid dbus.out of {0}: field {1} of {2} {3}'.format(
+            self.qualified_name, field.name, self.type.name, reason))
+    return self.type.reference.fields
+
+
+def go_result_types(self):
+    fields = out_fields(self)
+    if fields:
+        return ''.join('{0}, '.format(go_type(field)) for field in fields)
+    return '{0}, '.format(go_type(self)) if has_return_value(self) else ''
+
+
+def go_results(self):
+    fields = out_fields(self)
+    if fields:
+        return ''.join('{0} {1}, '.format(field.name, go_type(field)) for field in fields)
+    return 'r {0}, '.format(go_type(self)) if has_return_value(self) else ''
+
+
+def go_result_refs(self):
+    fields = out_fields(self)
+    if fields:
+        return ', '.join('&' + field.name for field in fields)
+    return '&r' if has_return_value(self) else ''
+
+
+
 def cap_name(self):
     return ' '.join(word[0].upper() + word[1:] for word in self.name.split())
 
@@ -63,23 +378,23 @@ def param_size(self):
     return len(self.parameters)
 
 
-def insert_unique_dependency_module(module, symbol, dependencies):
-    deducted_symbol = symbol.type.nested if symbol.type.nested else symbol
-    if not deducted_symbol.type.is_primitive:
-        found_symbol = module.lookup(deducted_symbol.type.qualified_name)
-        if found_symbol:
-            dependency = found_symbol.module
-            if dependency and dependency.module != deducted_symbol.type.module and dependency not in dependencies:
-                dependencies.append(dependency)
+def insert_unique_dependency_module(module, type, dependencies):
+    """Appends the modules other than module declaring the types referred by type at any depth to dependencies"""
+    if type.nested:
+        insert_unique_dependency_module(module, type.nested, dependencies)
+    elif type.is_complex and type.reference:
+        dependency = type.reference.module
+        if dependency is not module and dependency not in dependencies:
+            dependencies.append(dependency)
 
 
 def base_dependencies(module, interface):
     dependencies = []
     for prop in interface.properties:
-        insert_unique_dependency_module(module, prop, dependencies)
+        insert_unique_dependency_module(module, prop.type, dependencies)
     for m in interface.signals:
         for param in m.parameters:
-            insert_unique_dependency_module(module, param, dependencies)
+            insert_unique_dependency_module(module, param.type, dependencies)
     return dependencies
 
 
@@ -87,14 +402,32 @@ def interface_dependencies(module, interface):
     dependencies = []
     for operation in interface.operations:
         for param in operation.parameters:
-            insert_unique_dependency_module(module, param, dependencies)
+            insert_unique_dependency_module(module, param.type, dependencies)
         if operation.has_return_value:
             insert_unique_dependency_module(module, operation.type, dependencies)
+        for field in out_fields(operation):
+            insert_unique_dependency_module(module, field.type, dependencies)
     for base_dependency in base_dependencies(module, interface):
-        dependencies.append(base_dependency)
+        if base_dependency not in dependencies:
+            dependencies.append(base_dependency)
     return dependencies
 
 
+def uses_variant(type):
+    if type.nested:
+        return uses_variant(type.nested)
+    return type.is_primitive and type.name == 'var'
+
+
+def base_uses_variant(self):
+    return any(uses_variant(prop.type) for interface in self.interfaces for prop in interface.properties) or \
+        any(uses_variant(param.type) for interface in self.interfaces for signal in interface.signals for param in signal.parameters)
+
+
+def struct_uses_variant(self):
+    return any(uses_variant(field.type) for struct in self.structs for field in struct.fields)
+
+
 def base_imports(self):
     imports = {}
     for interface in self.interfaces:
@@ -116,24 +449,123 @@ def struct_imports(self):
     dependencies = []
     for struct in self.structs:
         for field in struct.fields:
-            insert_unique_dependency_module(self, field, dependencies)
+            insert_unique_dependency_module(self, field.type, dependencies)
     for dependency in dependencies:
         imports[''.join(dependency.name_parts)] = dependency.tags.get('gomod')
     return imports
 
 
 def unique_enum_name(self):
-    module_members = []
-    for enum in self.module.enums:
-        for member in enum.members:
-            if member == self:
-                if member.name not in module_members:
-                    return self.name
+    """Members are named as in qface, or prefixed by the name of their enum if it is annotated by @prefix: true"""
+    return self.enum.name + self.name if self.enum.tags.get('prefix') else self.name
+
+
+def module_version(self):
+    version = str(self.version)
+    if not re.match(r'^[0-9]+\.[0-9]+$', version):
+        raise ValueError('Invalid version of {0}: {1}, expected major.minor'.format(self.name, version))
+    return version
+
+
+reserved_property_names = ('ready', 'version')
+
+
+def check_property_names(module):
+    for interface in module.interfaces:
+        for prop in interface.properties:
+            if prop.name in reserved_property_names:
+                raise ValueError('Property {0} clashes with the property {1} provided by every adapter'.format(
+                    prop.qualified_name, prop.name))
+
+
+def check_parameter_names(module):
+    for interface in module.interfaces:
+        for operation in interface.operations:
+            for parameter in operation.parameters:
+                if parameter.name in go_keywords:
+                    reason = 'is a Go keyword'
+                elif parameter.name in reserved_operation_names:
+                    reason = 'shadows an identifier used by the generated code'
                 else:
-                    return self.enum.name + self.name
+                    continue
+                raise ValueError('Invalid parameter {0} of {1}: {2}'.format(
+                    parameter.name, operation.qualified_name, reason))
+
+
+def check_enum_names(module):
+    names = {struct.name: struct.qualified_name for struct in module.structs}
+    names.update({enum.name: enum.qualified_name for enum in module.enums})
+    for enum in module.enums:
+        values = {}
+        for member in enum.members:
+            name = unique_enum_name(member)
+            if name in names:
+                raise ValueError('Member {0} of {1} clashes with {2}, annotate {3} with @prefix: true'.format(
+                    member.name, enum.qualified_name, names[name], enum.name))
+            names[name] = member.qualified_name
+            # members of the same value would be duplicate cases of the generated switches
+            if member.value in values:
+                raise ValueError('Member {0} of {1} has the value {2} of {3}'.format(
+                    member.name, enum.qualified_name, member.value, values[member.value]))
+            values[member.value] = member.name
+
+
+constraint_tags = ('range', 'maxLength', 'pattern', 'nonEmpty')
+
+
+def constraints(self):
+    """Returns the constraint annotations of self, parameters are constrained by annotations of their operation
+    mapping parameter names to constraints as qface has no annotations of parameters"""
+    if isinstance(self, qface.idl.domain.Parameter):
+        operation_tags = getattr(self.operation, 'tags', {})
+        return {tag: operation_tags[tag][self.name] for tag in constraint_tags
+                if isinstance(operation_tags.get(tag), dict) and self.name in operation_tags[tag]}
+    return {tag: self.tags[tag] for tag in constraint_tags if tag in self.tags}
+
+
+def type_needs_validation(type, seen):
+    if type.nested:
+        return type_needs_validation(type.nested, seen)
+    elif type.is_enum or type.is_flag:
+        return True
+    elif type.is_struct and type.reference and type.reference not in seen:
+        seen.add(type.reference)
+        return any(constraints(field) or type_needs_validation(field.type, seen) for field in type.reference.fields)
+    return False
+
+
+def go_checks(self, expr):
+    """Returns Go expressions of type error checking the constraints of self and the enums and structs it contains"""
+    checks = []
+    type = self.type
+    for tag, value in constraints(self).items():
+        if tag == 'range':
+            if not (type.is_int or type.is_real) or not isinstance(value, list) or len(value) != 2 or \
+                    any(isinstance(v, bool) or not isinstance(v, (int, float)) for v in value) or value[0] > value[1]:
+                raise ValueError('Invalid range of {0}: {1}, expected [min, max] of an int or real'.format(self.qualified_name, value))
+            bounds = [str(int(v)) if type.is_int else repr(float(v)) for v in value]
+            if type.is_int and any(v != int(v) for v in value):
+                raise ValueError('Invalid range of {0}: {1} are no ints'.format(self.qualified_name, value))
+            checks.append('goqface.CheckRange("{0}", {1}, {2}, {3})'.format(self.name, expr, bounds[0], bounds[1]))
+        elif tag == 'maxLength':
+            if not (type.is_string or type.is_list or type.is_map) or isinstance(value, bool) or not isinstance(value, int) or value < 0:
+                raise ValueError('Invalid maxLength of {0}: {1}, expected a count of a string, list or map'.format(self.qualified_name, value))
+            if type.is_string:
+                checks.append('goqface.CheckMaxLength("{0}", {1}, {2})'.format(self.name, expr, value))
             else:
-                module_members.append(member.name)
-    raise Exception("This should logically never happen")
+                checks.append('goqface.CheckMaxCount("{0}", len({1}), {2})'.format(self.name, expr, value))
+        elif tag == 'pattern':
+            if not type.is_string or not isinstance(value, str):
+                raise ValueError('Invalid pattern of {0}: {1}, expected a regular expression of a string'.format(self.qualified_name, value))
+            checks.append('goqface.CheckPattern("{0}", {1}, {2})'.format(self.name, expr, json.dumps(value)))
+        elif tag == 'nonEmpty':
+            if not (type.is_string or type.is_list or type.is_map) or not isinstance(value, bool):
+                raise ValueError('Invalid nonEmpty of {0}: {1}, expected true or false of a string, list or map'.format(self.qualified_name, value))
+            if value:
+                checks.append('goqface.CheckNonEmpty("{0}", len({1}))'.format(self.name, expr))
+    if type_needs_validation(type, set()):
+        checks.append('goqface.CheckValidField("{0}", {1})'.format(self.name, expr))
+    return checks
 
 
 def get_go_mod_path(path: Path):
@@ -197,6 +629,15 @@ Generator.strict = True
 setattr(qface.idl.domain.Module, 'interface_imports', property(interface_imports))
 setattr(qface.idl.domain.Module, 'base_imports', property(base_imports))
 setattr(qface.idl.domain.Module, 'struct_imports', property(struct_imports))
+setattr(qface.idl.domain.Module, 'base_uses_variant', property(base_uses_variant))
+setattr(qface.idl.domain.Module, 'struct_uses_variant', property(struct_uses_variant))
+
+setattr(qface.idl.domain.Symbol, 'doc', property(doc))
+setattr(qface.idl.domain.Symbol, 'go_doc', property(go_doc))
+setattr(qface.idl.domain.Symbol, 'doc_literal', property(doc_literal))
+setattr(qface.idl.domain.Symbol, 'deprecated', property(is_deprecated))
+setattr(qface.idl.domain.Symbol, 'go_deprecation', property(go_deprecation))
+setattr(qface.idl.domain.Module, 'go_version', property(module_version))
 
 setattr(qface.idl.domain.TypeSymbol, 'go_type', property(go_type))
 setattr(qface.idl.domain.Field, 'go_type', property(go_type))
@@ -206,9 +647,31 @@ setattr(qface.idl.domain.Parameter, 'go_type', property(go_type))
 
 setattr(qface.idl.domain.Field, 'cap_name', property(cap_name))
 
+setattr(qface.idl.domain.Field, 'go_default', property(go_default))
+setattr(qface.idl.domain.Field, 'json_name', property(json_name))
+setattr(qface.idl.domain.Field, 'go_equal', go_equal)
+setattr(qface.idl.domain.Field, 'go_clone', go_clone)
+setattr(qface.idl.domain.Property, 'go_equal', go_equal)
+setattr(qface.idl.domain.Property, 'go_clone', go_clone)
+setattr(qface.idl.domain.Property, 'go_default', property(go_default))
+setattr(qface.idl.domain.Interface, 'ready_default', property(ready_default))
+setattr(qface.idl.domain.Operation, 'access_rules', property(access_rules))
+setattr(qface.idl.domain.Operation, 'error_names', property(error_names))
+setattr(qface.idl.domain.Module, 'error_names', property(module_error_names))
+setattr(qface.idl.domain.Property, 'access_rules', property(access_rules))
+setattr(qface.idl.domain.Operation, 'go_access', property(go_access))
+setattr(qface.idl.domain.Property, 'go_access', property(go_access))
+setattr(qface.idl.domain.Property, 'go_emits', property(go_emits))
+
 setattr(qface.idl.domain.EnumMember, 'unique_name', property(unique_enum_name))
+setattr(qface.idl.domain.Field, 'go_checks', go_checks)
+setattr(qface.idl.domain.Property, 'go_checks', go_checks)
+setattr(qface.idl.domain.Parameter, 'go_checks', go_checks)
 
 setattr(qface.idl.domain.Operation, 'has_return_value', property(has_return_value))
+setattr(qface.idl.domain.Operation, 'go_result_types', property(go_result_types))
+setattr(qface.idl.domain.Operation, 'go_results', property(go_results))
+setattr(qface.idl.domain.Operation, 'go_result_refs', property(go_result_refs))
 setattr(qface.idl.domain.Operation, 'cap_name', property(cap_name))
 setattr(qface.idl.domain.Operation, 'lower_name', property(lower_name))
 
@@ -219,6 +682,8 @@ setattr(qface.idl.domain.Interface, 'proxy_name', property(proxy_name))
 setattr(qface.idl.domain.Property, 'lower_name', property(lower_name))
 setattr(qface.idl.domain.Property, 'cap_name', property(cap_name))
 
+setattr(qface.idl.domain.Parameter, 'cap_name', property(cap_name))
+
 setattr(qface.idl.domain.Signal, 'cap_name', property(cap_name))
 setattr(qface.idl.domain.Signal, 'lower_name', property(lower_name))
 setattr(qface.idl.domain.Signal, 'param_size', property(param_size))
@@ -241,6 +706,9 @@ def generate():
             ctx.update({'module': module})
             module_path = '/'.join(module.name_parts)
             ctx.update({'path': module_path})
+            check_enum_names(module)
+            check_property_names(module)
+            check_parameter_names(module)
             if module.interfaces:
                 generator.write('{{path}}/' + module.name_parts[-1].lower() + '_interface.go', 'interface.go.template', ctx)
                 generator.write('{{path}}/' + module.name_parts[-1].lower() + '_base.go', 'base.go.template', ctx)
@@ -253,4 +721,8 @@ def generate():
 
 
 if __name__ == '__main__':
+    if sys.argv[1:2] == ['compat']:
+        import compat
+        sys.exit(compat.main(sys.argv[2:]))
+    args = parser.parse_args()
     generate()
@@ -0,0 +1,248 @@
+"""Compares two revisions of qface modules and reports breaking changes for existing clients versus compatible additions.
+
+Both the D-Bus signatures and the generated Go API are regarded as the contract of a module, hence renaming struct fields,
+enum members or signal parameters is breaking while renaming operation parameters is not.
+"""
+from qface.generator import FileSystem
+from qface.idl.domain import Parameter
+import argparse
+import json
+import sys
+
+
+class Report:
+    def __init__(self):
+        self.modules = []
+        self.changes = []
+
+    def add(self, module, symbol, change, breaking, message):
+        self.changes.append({
+            'module': module.name,
+            'symbol': symbol.qualified_name,
+            'change': change,
+            'breaking': breaking,
+            'message': message,
+        })
+
+    def breaking(self, module, symbol, change, message):
+        self.add(module, symbol, change, True, message)
+
+    def compatible(self, module, symbol, change, message):
+        self.add(module, symbol, change, False, message)
+
+    def unexpected(self):
+        """Returns the breaking changes of modules whose major version was not increased"""
+        bumped = {module['name'] for module in self.modules if module['majorVersionBumped']}
+        return [change for change in self.changes if change['breaking'] and change['module'] not in bumped]
+
+    def as_dict(self):
+        return {'compatible': not self.unexpected(), 'modules': self.modules, 'changes': self.changes}
+
+
+def major_version(module):
+    return int(str(module.version).partition('.')[0])
+
+
+def type_name(type, keys):
+    """Returns a description of type identifying it across revisions, referenced types by their qualified name"""
+    if type.is_list:
+        return 'list<{0}>'.format(type_name(type.nested, keys))
+    elif type.is_map:
+        key = str(keys[0]) if keys else 'string'
+        return 'map<{0}, {1}>'.format(key, type_name(type.nested, keys[1:]))
+    elif type.is_complex and type.reference:
+        return type.reference.qualified_name
+    return type.name
+
+
+def symbol_type(symbol):
+    if isinstance(symbol, Parameter):
+        # keyed by a mapping of parameter names annotating the operation or signal
+        keys = getattr(symbol.operation, 'tags', {}).get('dbus.key')
+        keys = keys.get(symbol.name, []) if isinstance(keys, dict) else []
+    else:
+        keys = getattr(symbol, 'tags', {}).get('dbus.key', [])
+        if isinstance(keys, dict):
+            keys = []
+    return type_name(symbol.type, keys if isinstance(keys, list) else [keys])
+
+
+def by_name(symbols):
+    return {symbol.name: symbol for symbol in symbols}
+
+
+def compare_members(report, module, kind, old, new, compare):
+    """Reports members of old missing in new as breaking and those added to new as compatible, compare is called with the others"""
+    old, new = by_name(old), by_name(new)
+    for name, symbol in old.items():
+        if name not in new:
+            report.breaking(module, symbol, 'removed', '{0} removed'.format(kind))
+        else:
+            compare(report, module, symbol, new[name])
+    for name, symbol in new.items():
+        if name not in old:
+            report.compatible(module, symbol, 'added', '{0} added'.format(kind))
+
+
+def compare_parameters(report, module, old, new, kind, names_breaking):
+    old_types = [symbol_type(parameter) for parameter in old.parameters]
+    new_types = [symbol_type(parameter) for parameter in new.parameters]
+    if old_types != new_types:
+        report.breaking(module, old, 'signature', '{0} parameters changed from ({1}) to ({2})'.format(
+            kind, ', '.join(old_types), ', '.join(new_types)))
+        return
+    old_names = [parameter.name for parameter in old.parameters]
+    new_names = [parameter.name for parameter in new.parameters]
+    if old_names != new_names:
+        report.add(module, old, 'renamed', names_breaking, '{0} parameters renamed from ({1}) to ({2})'.format(
+            kind, ', '.join(old_names), ', '.join(new_names)))
+
+
+def compare_operation(report, module, old, new):
+    compare_parameters(report, module, old, new, 'operation', False)
+    old_out, new_out = bool(old.tags.get('dbus.out')), bool(new.tags.get('dbus.out'))
+    if symbol_type(old) != symbol_type(new) or old_out != new_out:
+        report.breaking(module, old, 'retyped', 'operation result changed from {0} to {1}'.format(
+            symbol_type(old) + (' (dbus.out)' if old_out else ''), symbol_type(new) + (' (dbus.out)' if new_out else '')))
+
+
+def emits(symbol):
+    return str(symbol.tags.get('dbus.emits', 'true')).lower()
+
+
+def compare_property(report, module, old, new):
+    if symbol_type(old) != symbol_type(new):
+        report.breaking(module, old, 'retyped', 'property type changed from {0} to {1}'.format(symbol_type(old), symbol_type(new)))
+    if not old.readonly and new.readonly:
+        report.breaking(module, old, 'readonly', 'property changed from writable to readonly')
+    elif old.readonly and not new.readonly:
+        report.compatible(module, old, 'writable', 'property changed from readonly to writable')
+    if emits(old) != emits(new):
+        # clients keep stale values of properties no longer announced by PropertiesChanged
+        report.add(module, old, 'emits', emits(old) in ('true', 'invalidates') and emits(new) in ('const', 'false'),
+                   'property dbus.emits changed from {0} to {1}'.format(emits(old), emits(new)))
+
+
+def compare_signal(report, module, old, new):
+    compare_parameters(report, module, old, new, 'signal', True)
+
+
+def compare_interface(report, module, old, new):
+    compare_members(report, module, 'operation', old.operations, new.operations, compare_operation)
+    compare_members(report, module, 'property', old.properties, new.properties, compare_property)
+    compare_members(report, module, 'signal', old.signals, new.signals, compare_signal)
+
+
+def compare_struct(report, module, old, new):
+    # D-Bus structs are sequences of their fields, any change of them changes the signature
+    old_fields = [(field.name, symbol_type(field)) for field in old.fields]
+    new_fields = [(field.name, symbol_type(field)) for field in new.fields]
+    if old_fields == new_fields:
+        return
+    new_by_name, old_by_name = dict(new_fields), dict(old_fields)
+    changes = len(report.changes)
+    for name, type in old_fields:
+        if name not in new_by_name:
+            report.breaking(module, old, 'field removed', 'field {0} removed'.format(name))
+        elif new_by_name[name] != type:
+            report.breaking(module, old, 'field retyped', 'field {0} changed from {1} to {2}'.format(name, type, new_by_name[name]))
+    for name, type in new_fields:
+        if name not in old_by_name:
+            report.breaking(module, old, 'field added', 'field {0} added, the signature of the struct changed'.format(name))
+    if len(report.changes) == changes:
+        report.breaking(module, old, 'fields reordered', 'fields reordered from ({0}) to ({1})'.format(
+            ', '.join(name for name, _ in old_fields), ', '.join(name for name, _ in new_fields)))
+
+
+def compare_enum(report, module, old, new):
+    if old.is_flag != new.is_flag:
+        report.breaking(module, old, 'retyped', 'changed from {0} to {1}'.format(
+            'flag' if old.is_flag else 'enum', 'flag' if new.is_flag else 'enum'))
+    old_members, new_members = by_name(old.members), by_name(new.members)
+    old_values = {member.value for member in old.members}
+    for member in old.members:
+        if member.name not in new_members:
+            report.breaking(module, old, 'member removed', 'member {0} removed'.format(member.name))
+        elif new_members[member.name].value != member.value:
+            report.breaking(module, old, 'member value', 'value of member {0} changed from {1} to {2}'.format(
+                member.name, member.value, new_members[member.name].value))
+    for member in new.members:
+        if member.name not in old_members:
+            if member.value in old_values:
+                report.breaking(module, old, 'member value', 'member {0} added with value {1} of a former member'.format(
+                    member.name, member.value))
+            else:
+                report.compatible(module, old, 'member added', 'member {0} added'.format(member.name))
+
+
+def compare_module(report, old, new):
+    report.modules.append({
+        'name': old.name,
+        'oldVersion': str(old.version),
+        'newVersion': str(new.version),
+        'majorVersionBumped': major_version(new) > major_version(old),
+    })
+    compare_members(report, old, 'interface', old.interfaces, new.interfaces, compare_interface)
+    compare_members(report, old, 'struct', old.structs, new.structs, compare_struct)
+    compare_members(report, old, 'enum', old.enums, new.enums, compare_enum)
+
+
+def compare(old_system, new_system, ignored=()):
+    """Returns the report of changes from the modules of old_system to those of new_system except modules named in ignored"""
+    report = Report()
+    old_modules = {name: module for name, module in by_name(old_system.modules).items() if name not in ignored}
+    new_modules = {name: module for name, module in by_name(new_system.modules).items() if name not in ignored}
+    for name, module in old_modules.items():
+        if name not in new_modules:
+            report.modules.append({'name': name, 'oldVersion': str(module.version), 'newVersion': None, 'majorVersionBumped': False})
+            report.breaking(module, module, 'removed', 'module removed')
+        else:
+            compare_module(report, module, new_modules[name])
+    for name, module in new_modules.items():
+        if name not in old_modules:
+            report.modules.append({'name': name, 'oldVersion': None, 'newVersion': str(module.version), 'majorVersionBumped': False})
+            report.compatible(module, module, 'added', 'module added')
+    return report
+
+
+def print_text(report, out):
+    unexpected = report.unexpected()
+    for change in report.changes:
+        if change in unexpected:
+            kind = 'breaking'
+        elif change['breaking']:
+            kind = 'breaking (major version increased)'
+        else:
+            kind = 'compatible'
+        out.write('{0}: {1}: {2}\n'.format(kind, change['symbol'], change['message']))
+    if unexpected:
+        out.write('{0} breaking changes, increase the major version of the module or revert them\n'.format(len(unexpected)))
+    else:
+        out.write('compatible\n')
+
+
+def main(argv):
+    parser = argparse.ArgumentParser(prog='codegen.py compat',
+                                     description='Compares two revisions of qface modules, exits with status 1 on breaking changes.')
+    parser.add_argument('--old', dest='old', type=str, required=True, nargs='+',
+                        help='qface files of the old revision or path to them, folders will be walked')
+    parser.add_argument('--new', dest='new', type=str, required=True, nargs='+',
+                        help='qface files of the new revision or path to them, folders will be walked')
+    parser.add_argument('--dependency', dest='dependency', type=str, required=False, nargs='+', default=[],
+                        help='path to .qface files of modules referenced by both revisions, which are not compared')
+    parser.add_argument('--format', dest='format', choices=('text', 'json'), default='text',
+                        help='format of the report written to stdout, default value is text')
+    args = parser.parse_args(argv)
+    FileSystem.strict = True
+    dependencies = {module.name for module in FileSystem.parse(args.dependency).modules} if args.dependency else set()
+    report = compare(FileSystem.parse(args.old + args.dependency), FileSystem.parse(args.new + args.dependency), dependencies)
+    if args.format == 'json':
+        json.dump(report.as_dict(), sys.stdout, indent=2)
+        sys.stdout.write('\n')
+    else:
+        print_text(report, sys.stdout)
+    return 1 if report.unexpected() else 0
+
+
+if __name__ == '__main__':
+    sys.exit(main(sys.argv[1:]))
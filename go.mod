@@ -1,5 +1,5 @@
 module github.com/idleroamer/goqface
 
-go 1.15
+go 1.18
 
-require github.com/godbus/dbus/v5 v5.0.4-0.20201111205956-e0a146e7de5d
+require github.com/godbus/dbus/v5 v5.1.0
@@ -0,0 +1,62 @@
+package goqface
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Caller identifies the peer sending a request
+type Caller struct {
+	// Sender is the unique bus name of the peer
+	Sender dbus.Sender
+	conn   *dbus.Conn
+}
+
+// NewCaller returns the Caller of a request sent by sender and received on conn
+func NewCaller(conn *dbus.Conn, sender dbus.Sender) Caller {
+	return Caller{Sender: sender, conn: conn}
+}
+
+// Credentials of a peer as known to the bus daemon
+type Credentials struct {
+	// UnixUserID is the uid of the peer process
+	UnixUserID uint32
+	// UnixGroupIDs are the gids of the peer process, nil if unknown to the bus daemon
+	UnixGroupIDs []uint32
+	// ProcessID is the pid of the peer process, zero if unknown to the bus daemon
+	ProcessID uint32
+	// LinuxSecurityLabel is the security context of the peer e.g. SELinux or AppArmor, empty if unknown
+	LinuxSecurityLabel string
+}
+
+// Credentials resolves the credentials of the caller by org.freedesktop.DBus.GetConnectionCredentials.
+// Credentials may be reused by another peer once the caller left the bus, hence resolve them while handling the request
+func (c Caller) Credentials(ctx context.Context) (Credentials, error) {
+	var credentials Credentials
+	if c.conn == nil {
+		return credentials, ErrNotConnected
+	}
+	var values map[string]dbus.Variant
+	err := c.conn.BusObject().CallWithContext(ctx, "org.freedesktop.DBus.GetConnectionCredentials", 0, string(c.Sender)).Store(&values)
+	if err != nil {
+		return credentials, err
+	}
+	uid, ok := values["UnixUserID"].Value().(uint32)
+	if !ok {
+		return credentials, fmt.Errorf("no unix user id of %s", c.Sender)
+	}
+	credentials.UnixUserID = uid
+	if gids, ok := values["UnixGroupIDs"].Value().([]uint32); ok {
+		credentials.UnixGroupIDs = gids
+	}
+	if pid, ok := values["ProcessID"].Value().(uint32); ok {
+		credentials.ProcessID = pid
+	}
+	if label, ok := values["LinuxSecurityLabel"].Value().([]byte); ok {
+		credentials.LinuxSecurityLabel = strings.TrimRight(string(label), "\x00")
+	}
+	return credentials, nil
+}
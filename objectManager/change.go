@@ -0,0 +1,7 @@
+package goqface
+
+// PropertyChange carries the previous and the current value of a changed property
+type PropertyChange[T any] struct {
+	Old T
+	New T
+}
@@ -0,0 +1,76 @@
+package goqface
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+)
+
+// Check returns the first of errs which is not nil, it is used to check all constraints of a value at once
+func Check(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckRange checks the @range constraint of name, value has to be within min and max inclusively
+func CheckRange[T int | float64](name string, value, min, max T) error {
+	if value < min || value > max {
+		return fmt.Errorf("%w: %s %v is out of range [%v, %v]", ErrInvalidArgs, name, value, min, max)
+	}
+	return nil
+}
+
+// CheckMaxLength checks the @maxLength constraint of a string, value may have at most max characters
+func CheckMaxLength(name string, value string, max int) error {
+	if length := utf8.RuneCountInString(value); length > max {
+		return fmt.Errorf("%w: %s has %d characters, at most %d allowed", ErrInvalidArgs, name, length, max)
+	}
+	return nil
+}
+
+// CheckMaxCount checks the @maxLength constraint of a list or map of count elements
+func CheckMaxCount(name string, count int, max int) error {
+	if count > max {
+		return fmt.Errorf("%w: %s has %d elements, at most %d allowed", ErrInvalidArgs, name, count, max)
+	}
+	return nil
+}
+
+// CheckNonEmpty checks the @nonEmpty constraint of a string, list or map of the given length
+func CheckNonEmpty(name string, length int) error {
+	if length == 0 {
+		return fmt.Errorf("%w: %s is empty", ErrInvalidArgs, name)
+	}
+	return nil
+}
+
+var patterns sync.Map
+
+// CheckPattern checks the @pattern constraint of name, value has to match the regular expression pattern
+func CheckPattern(name string, value string, pattern string) error {
+	compiled, ok := patterns.Load(pattern)
+	if !ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern of %s: %w", name, err)
+		}
+		compiled, _ = patterns.LoadOrStore(pattern, re)
+	}
+	if !compiled.(*regexp.Regexp).MatchString(value) {
+		return fmt.Errorf("%w: %s %q does not match %s", ErrInvalidArgs, name, value, pattern)
+	}
+	return nil
+}
+
+// CheckValidField checks the enums and structs in value by CheckValid, errors are prefixed by name
+func CheckValidField(name string, value interface{}) error {
+	if err := CheckValid(value); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package goqface
+
+import (
+	"sync"
+)
+
+// Dispatcher runs the notifications of observers, Dispatch is called while the notifying object is locked
+// and must therefore not run f synchronously
+type Dispatcher interface {
+	Dispatch(f func())
+}
+
+// GoroutineDispatcher runs each notification in a goroutine of its own,
+// hence notifications might be delivered concurrently and out of order
+type GoroutineDispatcher struct{}
+
+// Dispatch runs f in a new goroutine
+func (GoroutineDispatcher) Dispatch(f func()) {
+	go f()
+}
+
+// SerialDispatcher runs notifications one after another in order of dispatching, the zero value is ready to use
+type SerialDispatcher struct {
+	queue   []func()
+	running bool
+	mutex   sync.Mutex
+}
+
+// Dispatch queues f to be run after all previously dispatched notifications
+func (d *SerialDispatcher) Dispatch(f func()) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.queue = append(d.queue, f)
+	if !d.running {
+		d.running = true
+		go d.run()
+	}
+}
+
+func (d *SerialDispatcher) run() {
+	for {
+		d.mutex.Lock()
+		if len(d.queue) == 0 {
+			d.running = false
+			d.mutex.Unlock()
+			return
+		}
+		f := d.queue[0]
+		d.queue[0] = nil
+		d.queue = d.queue[1:]
+		d.mutex.Unlock()
+		f()
+	}
+}
@@ -0,0 +1,62 @@
+package goqface
+
+import (
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+// DocStringAnnotation is the introspection annotation carrying the documentation of an element
+const DocStringAnnotation = "org.freedesktop.DBus.DocString"
+
+// DeprecatedAnnotation is the introspection annotation marking an element annotated by @deprecated in qface
+const DeprecatedAnnotation = "org.freedesktop.DBus.Deprecated"
+
+// Docs holds the documentation of an interface and its members as declared in qface
+type Docs struct {
+	Interface  string
+	Methods    map[string]string
+	Properties map[string]string
+	Signals    map[string]string
+	// Deprecated holds the members annotated by @deprecated
+	Deprecated Deprecations
+}
+
+// Deprecations holds the names of deprecated members of an interface
+type Deprecations struct {
+	Methods    map[string]bool
+	Properties map[string]bool
+	Signals    map[string]bool
+}
+
+var deprecated = introspect.Annotation{Name: DeprecatedAnnotation, Value: "true"}
+
+// Annotate attaches the documentation as DocString annotations and deprecations as Deprecated annotations
+// to the introspection data of the interface
+func (d Docs) Annotate(i *introspect.Interface) {
+	if d.Interface != "" {
+		i.Annotations = append(i.Annotations, introspect.Annotation{Name: DocStringAnnotation, Value: d.Interface})
+	}
+	for j := range i.Methods {
+		if doc, ok := d.Methods[i.Methods[j].Name]; ok {
+			i.Methods[j].Annotations = append(i.Methods[j].Annotations, introspect.Annotation{Name: DocStringAnnotation, Value: doc})
+		}
+		if d.Deprecated.Methods[i.Methods[j].Name] {
+			i.Methods[j].Annotations = append(i.Methods[j].Annotations, deprecated)
+		}
+	}
+	for j := range i.Properties {
+		if doc, ok := d.Properties[i.Properties[j].Name]; ok {
+			i.Properties[j].Annotations = append(i.Properties[j].Annotations, introspect.Annotation{Name: DocStringAnnotation, Value: doc})
+		}
+		if d.Deprecated.Properties[i.Properties[j].Name] {
+			i.Properties[j].Annotations = append(i.Properties[j].Annotations, deprecated)
+		}
+	}
+	for j := range i.Signals {
+		if doc, ok := d.Signals[i.Signals[j].Name]; ok {
+			i.Signals[j].Annotations = append(i.Signals[j].Annotations, introspect.Annotation{Name: DocStringAnnotation, Value: doc})
+		}
+		if d.Deprecated.Signals[i.Signals[j].Name] {
+			i.Signals[j].Annotations = append(i.Signals[j].Annotations, deprecated)
+		}
+	}
+}
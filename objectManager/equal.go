@@ -0,0 +1,70 @@
+package goqface
+
+import (
+	"reflect"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// EqualSlices reports whether a and b have the same length and equal elements, nil and empty slices are equal
+func EqualSlices[T any](a, b []T, equal func(T, T) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualMaps reports whether a and b have the same keys with equal values, nil and empty maps are equal
+func EqualMaps[K comparable, V any](a, b map[K]V, equal func(V, V) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		w, ok := b[k]
+		if !ok || !equal(v, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualVariants reports whether a and b have the same signature and deeply equal values
+func EqualVariants(a, b dbus.Variant) bool {
+	return a.Signature() == b.Signature() && reflect.DeepEqual(a.Value(), b.Value())
+}
+
+// CloneSlice returns a copy of s with elements copied by clone, or assigned if clone is nil
+func CloneSlice[T any](s []T, clone func(T) T) []T {
+	if s == nil {
+		return nil
+	}
+	c := make([]T, len(s))
+	if clone == nil {
+		copy(c, s)
+		return c
+	}
+	for i, v := range s {
+		c[i] = clone(v)
+	}
+	return c
+}
+
+// CloneMap returns a copy of m with values copied by clone, or assigned if clone is nil
+func CloneMap[K comparable, V any](m map[K]V, clone func(V) V) map[K]V {
+	if m == nil {
+		return nil
+	}
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		if clone != nil {
+			v = clone(v)
+		}
+		c[k] = v
+	}
+	return c
+}
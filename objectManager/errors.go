@@ -0,0 +1,101 @@
+package goqface
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Names of standard D-Bus errors reported to peers
+const (
+	FailedErrorName           = "org.freedesktop.DBus.Error.Failed"
+	InvalidArgsErrorName      = "org.freedesktop.DBus.Error.InvalidArgs"
+	AccessDeniedErrorName     = "org.freedesktop.DBus.Error.AccessDenied"
+	UnknownInterfaceErrorName = "org.freedesktop.DBus.Error.UnknownInterface"
+	UnknownPropertyErrorName  = "org.freedesktop.DBus.Error.UnknownProperty"
+	PropertyReadOnlyErrorName = "org.freedesktop.DBus.Error.PropertyReadOnly"
+)
+
+// ErrNotConnected is returned by proxies on requests to the remote object before it is connected
+var ErrNotConnected = errors.New("not connected to the remote object")
+
+// ErrInvalidArgs is reported to peers as org.freedesktop.DBus.Error.InvalidArgs, wrap it to reject a value
+var ErrInvalidArgs = errors.New("invalid arguments")
+
+// ErrAccessDenied is reported to peers as org.freedesktop.DBus.Error.AccessDenied, wrap it to refuse a caller
+var ErrAccessDenied = errors.New("access denied")
+
+// MakeError converts err to the D-Bus error reported to peers. A dbus.Error is passed as is, errors wrapping
+// a DomainError, ErrInvalidArgs or ErrAccessDenied are named accordingly and any other error is named fallback
+func MakeError(err error, fallback string) *dbus.Error {
+	var dbusErr *dbus.Error
+	if errors.As(err, &dbusErr) {
+		return dbusErr
+	}
+	var dbusErrValue dbus.Error
+	if errors.As(err, &dbusErrValue) {
+		return &dbusErrValue
+	}
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return dbus.NewError(domainErr.Name, []interface{}{err.Error()})
+	}
+	name := fallback
+	switch {
+	case errors.Is(err, ErrInvalidArgs):
+		name = InvalidArgsErrorName
+	case errors.Is(err, ErrAccessDenied):
+		name = AccessDeniedErrorName
+	}
+	return dbus.NewError(name, []interface{}{err.Error()})
+}
+
+// DomainError is an error declared by the @errors annotation of qface operations, identified by its D-Bus error name
+type DomainError struct {
+	// Name is the D-Bus error name e.g. Tests.AddressBook.Error.NotFound
+	Name string
+	// Message describes the occurrence of the error, empty for the declared error itself
+	Message string
+}
+
+// Error returns the message of e, its name if there is none
+func (e *DomainError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return e.Name
+}
+
+// Is reports whether target is a DomainError of the same name, regardless of the message
+func (e *DomainError) Is(target error) bool {
+	t, ok := target.(*DomainError)
+	return ok && t.Name == e.Name
+}
+
+// DBusError names the error reported to peers if e is returned by a method
+func (e *DomainError) DBusError() (string, []interface{}) {
+	return e.Name, []interface{}{e.Error()}
+}
+
+// New returns the error e with a formatted message, to be returned by implementations of methods
+func (e *DomainError) New(format string, args ...interface{}) *dbus.Error {
+	return dbus.NewError(e.Name, []interface{}{fmt.Sprintf(format, args...)})
+}
+
+// ErrorDomain are the errors declared by the operations of a qface module
+type ErrorDomain []*DomainError
+
+// Decode returns a DomainError for a dbus.Error named like one of the domain, err otherwise
+func (d ErrorDomain) Decode(err error) error {
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) {
+		return err
+	}
+	for _, domainErr := range d {
+		if domainErr.Name == dbusErr.Name {
+			return &DomainError{Name: dbusErr.Name, Message: dbusErr.Error()}
+		}
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package goqface
+
+import (
+	"context"
+	"sync"
+)
+
+// DropPolicy decides what happens to an event which doesn't fit the buffer of a subscription
+type DropPolicy int
+
+const (
+	// Block delays delivery of the event until the subscriber catches up
+	Block DropPolicy = iota
+	// DropNewest discards the event which doesn't fit the buffer
+	DropNewest
+	// DropOldest discards the oldest buffered event in favour of the new one
+	DropOldest
+)
+
+// DefaultBufferSize is the buffer size of subscriptions unless set by WithBufferSize
+const DefaultBufferSize = 16
+
+type subscriptionOptions struct {
+	bufferSize int
+	dropPolicy DropPolicy
+}
+
+// SubscriptionOption configures a channel subscription
+type SubscriptionOption func(*subscriptionOptions)
+
+// WithBufferSize sets the number of events buffered for a subscriber, negative sizes are treated as 0 for an unbuffered channel
+func WithBufferSize(size int) SubscriptionOption {
+	return func(o *subscriptionOptions) {
+		if size < 0 {
+			size = 0
+		}
+		o.bufferSize = size
+	}
+}
+
+// WithDropPolicy sets the policy applied once the buffer of a subscriber is full, default is Block
+func WithDropPolicy(policy DropPolicy) SubscriptionOption {
+	return func(o *subscriptionOptions) {
+		o.dropPolicy = policy
+	}
+}
+
+type subscription[T any] struct {
+	ch         chan T
+	dropPolicy DropPolicy
+	done       <-chan struct{}
+}
+
+func (s *subscription[T]) send(v T) {
+	switch s.dropPolicy {
+	case DropNewest:
+		select {
+		case s.ch <- v:
+		default:
+		}
+	case DropOldest:
+		if cap(s.ch) == 0 {
+			// nothing buffered to be dropped instead
+			select {
+			case s.ch <- v:
+			default:
+			}
+			return
+		}
+		for {
+			select {
+			case s.ch <- v:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+			default:
+			}
+		}
+	default:
+		select {
+		case s.ch <- v:
+		case <-s.done:
+		}
+	}
+}
+
+// Feed delivers events in order of sending to subscribed channels, the zero value is ready to use
+type Feed[T any] struct {
+	subscriptions []*subscription[T]
+	mutex         sync.Mutex
+}
+
+// Subscribe returns a channel receiving the events sent after subscription, the channel is closed once ctx is done
+func (f *Feed[T]) Subscribe(ctx context.Context, opts ...SubscriptionOption) <-chan T {
+	options := subscriptionOptions{bufferSize: DefaultBufferSize, dropPolicy: Block}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	s := &subscription[T]{ch: make(chan T, options.bufferSize), dropPolicy: options.dropPolicy, done: ctx.Done()}
+	f.mutex.Lock()
+	f.subscriptions = append(f.subscriptions, s)
+	f.mutex.Unlock()
+	go func() {
+		<-ctx.Done()
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		for i := range f.subscriptions {
+			if f.subscriptions[i] == s {
+				f.subscriptions = append(f.subscriptions[:i], f.subscriptions[i+1:]...)
+				break
+			}
+		}
+		close(s.ch)
+	}()
+	return s.ch
+}
+
+// Len returns the number of subscribers
+func (f *Feed[T]) Len() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.subscriptions)
+}
+
+// Send delivers v to all subscribers, concurrent calls are delivered in the order they acquire the feed
+func (f *Feed[T]) Send(v T) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, s := range f.subscriptions {
+		s.send(v)
+	}
+}
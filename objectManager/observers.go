@@ -0,0 +1,79 @@
+package goqface
+
+import (
+	"sync"
+)
+
+type observerEntry[F any] struct {
+	key      interface{}
+	callback F
+}
+
+// subscriptionKey identifies callbacks registered by Subscribe
+type subscriptionKey uint64
+
+// Observers is a registry of callbacks of type F, the zero value is ready to use
+type Observers[F any] struct {
+	entries []observerEntry[F]
+	lastKey subscriptionKey
+	mutex   sync.Mutex
+}
+
+// Add registers the callback of an observer identified by key, e.g. the observer itself.
+// It returns false without registering the callback if key is already registered
+func (o *Observers[F]) Add(key interface{}, callback F) bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	for _, entry := range o.entries {
+		if entry.key == key {
+			return false
+		}
+	}
+	o.entries = append(o.entries, observerEntry[F]{key: key, callback: callback})
+	return true
+}
+
+// Remove unregisters the callback registered for key and reports whether it was registered
+func (o *Observers[F]) Remove(key interface{}) bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	for i, entry := range o.entries {
+		if entry.key == key {
+			entries := make([]observerEntry[F], 0, len(o.entries)-1)
+			entries = append(entries, o.entries[:i]...)
+			o.entries = append(entries, o.entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe registers callback and returns a function to unregister it again, which might be called more than once
+func (o *Observers[F]) Subscribe(callback F) (unsubscribe func()) {
+	o.mutex.Lock()
+	o.lastKey++
+	key := o.lastKey
+	o.entries = append(o.entries, observerEntry[F]{key: key, callback: callback})
+	o.mutex.Unlock()
+	return func() {
+		o.Remove(key)
+	}
+}
+
+// Len returns the number of registered callbacks
+func (o *Observers[F]) Len() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return len(o.entries)
+}
+
+// Callbacks returns the currently registered callbacks in order of registration
+func (o *Observers[F]) Callbacks() []F {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	callbacks := make([]F, len(o.entries))
+	for i, entry := range o.entries {
+		callbacks[i] = entry.callback
+	}
+	return callbacks
+}
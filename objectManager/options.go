@@ -0,0 +1,107 @@
+package goqface
+
+import (
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Options configures generated adapters and proxies, zero values leave the generated defaults in place
+type Options struct {
+	ObjectPath    dbus.ObjectPath
+	InterfaceName string
+	ServiceName   string
+	Dispatcher    Dispatcher
+	// RefreshInterval enables periodic refreshes of all properties of a proxy if positive
+	RefreshInterval time.Duration
+	// SetMode controls how setters of a proxy update the cached value of a property
+	SetMode SetMode
+	// SetTimeout bounds how long a proxy setter waits for confirmation, DefaultSetTimeout if not positive
+	SetTimeout time.Duration
+	// Policy authorizes method calls and property writes received by an adapter, RulePolicy if nil
+	Policy Policy
+}
+
+// SetMode controls how setters of a proxy update the cached value of a property
+type SetMode int
+
+const (
+	// SetAsync leaves the cached value to be updated once the PropertiesChanged signal is received
+	SetAsync SetMode = iota
+	// SetConfirmed blocks the setter until the PropertiesChanged signal carrying the value is received, the value is fetched if it times out.
+	// Properties not emitting their values are fetched right after the write
+	SetConfirmed
+	// SetOptimistic updates the cached value right away and restores the previous value if the remote object rejects it
+	SetOptimistic
+)
+
+// DefaultSetTimeout is the time a proxy setter waits for confirmation by default
+const DefaultSetTimeout = time.Second
+
+// Option sets a field of Options
+type Option func(*Options)
+
+// NewOptions returns Options with all opts applied in order
+func NewOptions(opts ...Option) Options {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithObjectPath overrides the object path derived from the qface interface name
+func WithObjectPath(objectPath dbus.ObjectPath) Option {
+	return func(o *Options) {
+		o.ObjectPath = objectPath
+	}
+}
+
+// WithInterfaceName overrides the dbus interface name derived from the qface interface name
+func WithInterfaceName(interfaceName string) Option {
+	return func(o *Options) {
+		o.InterfaceName = interfaceName
+	}
+}
+
+// WithServiceName sets the bus name to be requested by an adapter or to be connected to by a proxy
+func WithServiceName(serviceName string) Option {
+	return func(o *Options) {
+		o.ServiceName = serviceName
+	}
+}
+
+// WithDispatcher sets how observers are notified, by default notifications are run in order by a SerialDispatcher
+func WithDispatcher(dispatcher Dispatcher) Option {
+	return func(o *Options) {
+		o.Dispatcher = dispatcher
+	}
+}
+
+// WithRefreshInterval makes a proxy fetch all properties every interval while connected, to recover from missed signals
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.RefreshInterval = interval
+	}
+}
+
+// WithSetMode sets how setters of a proxy update the cached value of a property
+func WithSetMode(mode SetMode) Option {
+	return func(o *Options) {
+		o.SetMode = mode
+	}
+}
+
+// WithSetTimeout sets how long a proxy setter in SetConfirmed mode waits for the PropertiesChanged signal
+func WithSetTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.SetTimeout = timeout
+	}
+}
+
+// WithPolicy sets the policy authorizing method calls and property writes received by an adapter
+func WithPolicy(policy Policy) Option {
+	return func(o *Options) {
+		o.Policy = policy
+	}
+}
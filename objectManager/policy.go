@@ -0,0 +1,136 @@
+package goqface
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// AccessRequest describes a method call or a property write to be authorized
+type AccessRequest struct {
+	Caller    Caller
+	Interface string
+	// Member is the name of the called method or the written property
+	Member string
+	// Write is true for writes of a property, false for method calls
+	Write bool
+	// Rules are the @access annotations of the member, empty if access is not restricted by the qface interface
+	Rules []string
+}
+
+// AccessRules are the @access annotations of the methods and writable properties of an interface by their names
+type AccessRules struct {
+	Methods    map[string][]string
+	Properties map[string][]string
+}
+
+// Of returns the rules of a method or, if write is true, of a property
+func (a AccessRules) Of(member string, write bool) []string {
+	if write {
+		return a.Properties[member]
+	}
+	return a.Methods[member]
+}
+
+// Policy decides whether a caller may call a method or write a property of an adapter
+type Policy interface {
+	// Authorize returns nil to grant access, an error is reported as AccessDenied unless it is a *dbus.Error
+	Authorize(ctx context.Context, request AccessRequest) error
+}
+
+// PolicyFunc adapts a function to Policy
+type PolicyFunc func(ctx context.Context, request AccessRequest) error
+
+// Authorize calls f
+func (f PolicyFunc) Authorize(ctx context.Context, request AccessRequest) error {
+	return f(ctx, request)
+}
+
+// RulePolicy grants access if the credentials of the caller match any of the rules of the request,
+// members without rules are accessible by everyone. Rules are of the form uid=<id>, gid=<id>, user=<name> or group=<name>.
+// It is the policy of adapters by default
+type RulePolicy struct{}
+
+// Authorize evaluates the rules of request against the credentials of the caller
+func (RulePolicy) Authorize(ctx context.Context, request AccessRequest) error {
+	if len(request.Rules) == 0 {
+		return nil
+	}
+	credentials, err := request.Caller.Credentials(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: credentials of %s unknown: %v", ErrAccessDenied, request.Caller.Sender, err)
+	}
+	for _, rule := range request.Rules {
+		granted, err := credentials.match(rule)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrAccessDenied, err)
+		}
+		if granted {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s may not access %s.%s", ErrAccessDenied, request.Caller.Sender, request.Interface, request.Member)
+}
+
+func (c Credentials) match(rule string) (bool, error) {
+	subject, value, _ := strings.Cut(rule, "=")
+	switch subject {
+	case "uid":
+		uid, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return false, fmt.Errorf("invalid access rule %s", rule)
+		}
+		return c.UnixUserID == uint32(uid), nil
+	case "user":
+		u, err := user.Lookup(value)
+		if err != nil {
+			return false, nil
+		}
+		return u.Uid == strconv.FormatUint(uint64(c.UnixUserID), 10), nil
+	case "gid":
+		gid, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return false, fmt.Errorf("invalid access rule %s", rule)
+		}
+		return c.inGroup(uint32(gid)), nil
+	case "group":
+		g, err := user.LookupGroup(value)
+		if err != nil {
+			return false, nil
+		}
+		gid, err := strconv.ParseUint(g.Gid, 10, 32)
+		if err != nil {
+			return false, nil
+		}
+		return c.inGroup(uint32(gid)), nil
+	}
+	return false, fmt.Errorf("invalid access rule %s", rule)
+}
+
+// inGroup tells whether the peer is a member of gid, by its group ids if known to the bus daemon, by the user database otherwise
+func (c Credentials) inGroup(gid uint32) bool {
+	if c.UnixGroupIDs != nil {
+		for _, id := range c.UnixGroupIDs {
+			if id == gid {
+				return true
+			}
+		}
+		return false
+	}
+	u, err := user.LookupId(strconv.FormatUint(uint64(c.UnixUserID), 10))
+	if err != nil {
+		return false
+	}
+	gids, err := u.GroupIds()
+	if err != nil {
+		return false
+	}
+	for _, id := range gids {
+		if id == strconv.FormatUint(uint64(gid), 10) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package goqface
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+// PropertiesChangedSignal is the member name of the signal emitted on changes of properties
+const PropertiesChangedSignal = "org.freedesktop.DBus.Properties.PropertiesChanged"
+
+// EmitsChangedSignalAnnotation is the introspection annotation telling how changes of a property are published
+const EmitsChangedSignalAnnotation = "org.freedesktop.DBus.Property.EmitsChangedSignal"
+
+// Property is a property of an interface exported by Properties
+type Property struct {
+	// Get returns the current value of the property
+	Get func() interface{}
+	// Set applies a value written by caller, the property is read-only if nil
+	Set func(caller Caller, value dbus.Variant) *dbus.Error
+	// Emit controls how changes of the property are published by PropertiesChanged
+	Emit prop.EmitType
+}
+
+// Properties implements org.freedesktop.DBus.Properties for an interface of an object.
+// Values are read from the implementation on request, hence they always agree with it
+type Properties struct {
+	conn  *dbus.Conn
+	path  dbus.ObjectPath
+	iface string
+	props map[string]*Property
+}
+
+// NewProperties returns the properties of iface at path on conn, to be exported by Export
+func NewProperties(conn *dbus.Conn, path dbus.ObjectPath, iface string, props map[string]*Property) *Properties {
+	return &Properties{conn: conn, path: path, iface: iface, props: props}
+}
+
+// Export exports the properties as org.freedesktop.DBus.Properties at their path
+func (p *Properties) Export() error {
+	return p.conn.Export(p, p.path, "org.freedesktop.DBus.Properties")
+}
+
+// Get implements org.freedesktop.DBus.Properties.Get
+func (p *Properties) Get(iface, name string) (dbus.Variant, *dbus.Error) {
+	if iface != p.iface {
+		return dbus.Variant{}, p.unknownInterface(iface)
+	}
+	property, ok := p.props[name]
+	if !ok {
+		return dbus.Variant{}, p.unknownProperty(name)
+	}
+	return dbus.MakeVariant(property.Get()), nil
+}
+
+// GetAll implements org.freedesktop.DBus.Properties.GetAll
+func (p *Properties) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	if iface != p.iface {
+		return nil, p.unknownInterface(iface)
+	}
+	values := make(map[string]dbus.Variant, len(p.props))
+	for name, property := range p.props {
+		values[name] = dbus.MakeVariant(property.Get())
+	}
+	return values, nil
+}
+
+// Set implements org.freedesktop.DBus.Properties.Set
+func (p *Properties) Set(sender dbus.Sender, iface, name string, value dbus.Variant) *dbus.Error {
+	if iface != p.iface {
+		return p.unknownInterface(iface)
+	}
+	property, ok := p.props[name]
+	if !ok {
+		return p.unknownProperty(name)
+	}
+	if property.Set == nil {
+		return dbus.NewError(PropertyReadOnlyErrorName, []interface{}{fmt.Sprintf("property %s of %s is read-only", name, p.iface)})
+	}
+	if expected := dbus.SignatureOf(property.Get()); value.Signature() != expected {
+		return dbus.NewError(InvalidArgsErrorName, []interface{}{
+			fmt.Sprintf("property %s of %s expects a value of signature %s, not %s", name, p.iface, expected, value.Signature())})
+	}
+	return property.Set(NewCaller(p.conn, sender), value)
+}
+
+func (p *Properties) unknownInterface(iface string) *dbus.Error {
+	return dbus.NewError(UnknownInterfaceErrorName, []interface{}{fmt.Sprintf("no interface %s at %s", iface, p.path)})
+}
+
+func (p *Properties) unknownProperty(name string) *dbus.Error {
+	return dbus.NewError(UnknownPropertyErrorName, []interface{}{fmt.Sprintf("no property %s of %s", name, p.iface)})
+}
+
+// Introspection returns the introspection data of the properties sorted by name
+func (p *Properties) Introspection() []introspect.Property {
+	names := make([]string, 0, len(p.props))
+	for name := range p.props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	properties := make([]introspect.Property, 0, len(names))
+	for _, name := range names {
+		property := p.props[name]
+		access := "read"
+		if property.Set != nil {
+			access = "readwrite"
+		}
+		properties = append(properties, introspect.Property{
+			Name:   name,
+			Type:   dbus.SignatureOf(property.Get()).String(),
+			Access: access,
+			Annotations: []introspect.Annotation{
+				{Name: EmitsChangedSignalAnnotation, Value: property.Emit.String()},
+			},
+		})
+	}
+	return properties
+}
+
+// Publish emits the changed properties in a single PropertiesChanged signal, either with their value or as invalidated
+// according to their emit type. Properties emitting no signal are left out
+func (p *Properties) Publish(changed map[string]interface{}) error {
+	values := make(map[string]dbus.Variant, len(changed))
+	invalidated := []string{}
+	for name, value := range changed {
+		property, ok := p.props[name]
+		if !ok {
+			continue
+		}
+		switch property.Emit {
+		case prop.EmitTrue:
+			values[name] = dbus.MakeVariant(value)
+		case prop.EmitInvalidates:
+			invalidated = append(invalidated, name)
+		}
+	}
+	if len(values) == 0 && len(invalidated) == 0 {
+		return nil
+	}
+	sort.Strings(invalidated)
+	return p.conn.Emit(p.path, PropertiesChangedSignal, p.iface, values, invalidated)
+}
@@ -0,0 +1,153 @@
+package goqface
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Adapter is implemented by generated adapters to be exported by a Server
+type Adapter interface {
+	Export() error
+	Close()
+	ServiceName() string
+}
+
+// Server owns a bus connection, the adapters exported on it and the bus names requested for them
+type Server struct {
+	conn     *dbus.Conn
+	adapters []Adapter
+	names    []string
+	closed   bool
+	mutex    sync.Mutex
+}
+
+// NewServer returns a server taking ownership of conn, which is closed along with the server.
+// Hence conn should be a private connection, see dbus.SessionBusPrivate
+func NewServer(conn *dbus.Conn) *Server {
+	return &Server{conn: conn}
+}
+
+// Conn returns the bus connection of the server
+func (s *Server) Conn() *dbus.Conn {
+	return s.conn
+}
+
+// Export exports the adapters to bus and requests their service names if set
+func (s *Server) Export(adapters ...Adapter) error {
+	for _, adapter := range adapters {
+		if err := adapter.Export(); err != nil {
+			return err
+		}
+		s.mutex.Lock()
+		s.adapters = append(s.adapters, adapter)
+		s.mutex.Unlock()
+		if adapter.ServiceName() != "" {
+			if err := s.RequestName(adapter.ServiceName()); err != nil {
+				s.remove(adapter)
+				adapter.Close()
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RequestName requests a bus name for the server, it fails if the name is owned by another connection
+func (s *Server) RequestName(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, n := range s.names {
+		if n == name {
+			return nil
+		}
+	}
+	reply, err := s.conn.RequestName(name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner && reply != dbus.RequestNameReplyAlreadyOwner {
+		return fmt.Errorf("bus name %s already taken", name)
+	}
+	s.names = append(s.names, name)
+	return nil
+}
+
+// Run blocks until ctx is done, the process receives SIGINT or SIGTERM or the connection is lost and closes the server afterwards
+func (s *Server) Run(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	select {
+	case <-ctx.Done():
+	case <-signals:
+	case <-s.conn.Context().Done():
+	}
+	return s.Close()
+}
+
+// Close closes the exported adapters in reverse order, releases the requested bus names and closes the connection.
+// The connection is closed even if names could not be released, the returned error holds all failures
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for i := len(s.adapters) - 1; i >= 0; i-- {
+		s.adapters[i].Close()
+	}
+	s.adapters = nil
+	var errs errorList
+	// names of a lost connection are released by the bus
+	if s.conn.Context().Err() == nil {
+		for _, name := range s.names {
+			if _, err := s.conn.ReleaseName(name); err != nil {
+				errs = append(errs, fmt.Errorf("release bus name %s: %w", name, err))
+			}
+		}
+	}
+	s.names = nil
+	if err := s.conn.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// remove forgets adapter failed to be exported
+func (s *Server) remove(adapter Adapter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, a := range s.adapters {
+		if a == adapter {
+			s.adapters = append(s.adapters[:i], s.adapters[i+1:]...)
+			return
+		}
+	}
+}
+
+// errorList holds the errors of several steps failed independently
+type errorList []error
+
+func (e errorList) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the errors of the list to be matched by errors.Is and errors.As
+func (e errorList) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,62 @@
+package goqface
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// validity is implemented by generated enums
+type validity interface {
+	IsValid() bool
+}
+
+// validator is implemented by generated structs checking the constraints of their fields
+type validator interface {
+	Validate() error
+}
+
+// CheckValid checks that all enums in value, also nested in structs, lists and maps, are members of their enum
+// and that structs satisfy the constraints of their fields. The returned error wraps ErrInvalidArgs
+func CheckValid(value interface{}) error {
+	return checkValid(reflect.ValueOf(value))
+}
+
+func checkValid(v reflect.Value) error {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		return checkValid(v.Elem())
+	}
+	if v.CanInterface() {
+		if e, ok := v.Interface().(validity); ok && !e.IsValid() {
+			return fmt.Errorf("%w: %v is no valid %s", ErrInvalidArgs, e, v.Type().Name())
+		}
+		// structs validate their fields including nested values on their own
+		if s, ok := v.Interface().(validator); ok {
+			return s.Validate()
+		}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := checkValid(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkValid(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := checkValid(iter.Value()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
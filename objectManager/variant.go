@@ -0,0 +1,49 @@
+package goqface
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// StoreVariant stores the value of a property received as variant in dest. Unlike dbus.Store it unwraps
+// values of var properties, hence a dest of type *dbus.Variant receives the variant sent by the remote object
+func StoreVariant(variant dbus.Variant, dest interface{}) error {
+	if v, ok := dest.(*dbus.Variant); ok {
+		inner, ok := variant.Value().(dbus.Variant)
+		if !ok {
+			return fmt.Errorf("%w: value of signature %s is no variant", ErrInvalidArgs, variant.Signature())
+		}
+		*v = inner
+		return nil
+	}
+	return dbus.Store([]interface{}{variant}, dest)
+}
+
+// VariantAs converts the value of v to T, e.g. an int32 to int or a struct received as []interface{} to a generated struct.
+// The signature of T has to match the one of v unless T is an interface or dbus.Variant
+func VariantAs[T any](v dbus.Variant) (T, error) {
+	var value T
+	t := reflect.TypeOf(&value).Elem()
+	if t.Kind() != reflect.Interface && t != variantType {
+		if expected := dbus.SignatureOfType(t); expected != v.Signature() {
+			return value, fmt.Errorf("%w: value of signature %s is no %s", ErrInvalidArgs, v.Signature(), expected)
+		}
+	}
+	err := dbus.Store([]interface{}{v.Value()}, &value)
+	return value, err
+}
+
+var variantType = reflect.TypeOf(dbus.Variant{})
+
+// Lookup returns the value of key in a dictionary of variants converted to T, false if it is missing or can't be converted
+func Lookup[T any](dict map[string]dbus.Variant, key string) (T, bool) {
+	v, ok := dict[key]
+	if !ok {
+		var value T
+		return value, false
+	}
+	value, err := VariantAs[T](v)
+	return value, err == nil
+}
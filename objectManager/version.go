@@ -0,0 +1,27 @@
+package goqface
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// VersionProperty is the read-only property of every adapter holding the version of its qface module
+const VersionProperty = "version"
+
+// ErrIncompatibleVersion is wrapped by errors of CheckVersion
+var ErrIncompatibleVersion = errors.New("incompatible version")
+
+// CheckVersion checks that the version of a remote object has the same major version as the local one, both of form major.minor.
+// An empty remote version is accepted as objects not generated by goqface lack the version property
+func CheckVersion(local, remote string) error {
+	if remote == "" {
+		return nil
+	}
+	localMajor, _, _ := strings.Cut(local, ".")
+	remoteMajor, _, _ := strings.Cut(remote, ".")
+	if localMajor != remoteMajor {
+		return fmt.Errorf("%w: remote version %s, expected major version %s", ErrIncompatibleVersion, remote, localMajor)
+	}
+	return nil
+}
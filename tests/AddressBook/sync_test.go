@@ -1,11 +1,16 @@
 package addressbook
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"os/user"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -68,41 +73,30 @@ func (c *AddressBookImpl) SelectContact(contactId int) *dbus.Error {
 		}
 	}
 	if !found {
-		return dbus.MakeFailedError(dbus.ErrMsgInvalidArg)
+		return AddressBook.ErrNotFound.New("no contact %d to select", contactId)
 	}
 	return nil
 }
 
 func (c *AddressBookImpl) DeleteContact(contactId int) (bool, *dbus.Error) {
-	found := false
-	i := 0
-	tmpContacts := c.Contacts()
-	for _, entry := range tmpContacts {
+	// Contacts returns a copy to be modified
+	contacts := c.Contacts()
+	for i, entry := range contacts {
 		if entry.Idx == contactId {
-			toBeDeletedContact := entry
-			c.ContactDeleted(toBeDeletedContact)
+			c.SetContacts(append(contacts[:i], contacts[i+1:]...))
+			c.ContactDeleted(entry)
 			fmt.Printf("DeleteContact: %d", contactId)
-			found = true
-		} else {
-			tmpContacts[i] = entry
-			i++
+			return true, nil
 		}
 	}
-	for j := i; j < len(tmpContacts); j++ {
-		fmt.Printf("newContactCreated: %v", len(c.Contacts()))
-		tmpContacts[j] = AddressBook.Contact{}
-	}
-	if found {
-		c.SetContacts(tmpContacts)
-	} else {
-		return true, dbus.MakeFailedError(dbus.ErrMsgInvalidArg)
-	}
-	return true, nil
+	return false, AddressBook.ErrNotFound.New("no contact %d to delete", contactId)
 }
 
 func (c *AddressBookImpl) UpdateContact(contactId int, contact AddressBook.Contact) *dbus.Error {
-	if contactId >= 0 && contactId < len(c.Contacts()) {
-		c.Contacts()[contactId] = contact
+	contacts := c.Contacts()
+	if contactId >= 0 && contactId < len(contacts) {
+		contacts[contactId] = contact
+		c.SetContacts(contacts)
 		fmt.Printf("UpdateContact: %v", contact)
 	} else {
 		c.ContactUpdateFailed(AddressBook.Other)
@@ -110,6 +104,15 @@ func (c *AddressBookImpl) UpdateContact(contactId int, contact AddressBook.Conta
 	return nil
 }
 
+func (c *AddressBookImpl) FindContact(name string) (AddressBook.Contact, int, *dbus.Error) {
+	for i, contact := range c.Contacts() {
+		if contact.Name == name {
+			return contact, i, nil
+		}
+	}
+	return AddressBook.Contact{}, -1, AddressBook.ErrNotFound.New("no contact named %s", name)
+}
+
 func (c *AddressBookImpl) SetCurrentContact(value AddressBook.Contact) error {
 	if value.Idx != -1 {
 		return c.AddressBookBase.SetCurrentContact(value)
@@ -118,6 +121,41 @@ func (c *AddressBookImpl) SetCurrentContact(value AddressBook.Contact) error {
 	}
 }
 
+type ValidatingAddressBookImpl struct {
+	*AddressBookImpl
+	writer dbus.Sender
+}
+
+func (c *ValidatingAddressBookImpl) ValidateContacts(caller goqface.Caller, contacts []AddressBook.Contact) error {
+	for _, contact := range contacts {
+		if contact.Name == "" {
+			return fmt.Errorf("contact %d without name", contact.Idx)
+		}
+	}
+	return nil
+}
+
+func (c *ValidatingAddressBookImpl) ValidateDebt(caller goqface.Caller, debt float64) error {
+	if caller.Sender != c.writer {
+		return fmt.Errorf("%w: %s may not change debt", goqface.ErrAccessDenied, caller.Sender)
+	}
+	return nil
+}
+
+type AuditingAddressBookImpl struct {
+	*AddressBookImpl
+	deletedBy []goqface.Credentials
+}
+
+func (c *AuditingAddressBookImpl) DeleteContactWithCaller(caller goqface.Caller, contactId int) (bool, *dbus.Error) {
+	credentials, err := caller.Credentials(context.Background())
+	if err != nil {
+		return false, goqface.MakeError(err, goqface.FailedErrorName)
+	}
+	c.deletedBy = append(c.deletedBy, credentials)
+	return c.DeleteContact(contactId)
+}
+
 func (c *AddressBookClient) OnContactsChanged(contacts []AddressBook.Contact) {
 	c.contactsChanged++
 	c.wg.Done()
@@ -153,6 +191,63 @@ func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
 	}
 }
 
+// fixture is an adapter exported on the session bus along with a ready proxy connected to it on a private connection
+type fixture struct {
+	server  *dbus.Conn
+	client  *dbus.Conn
+	adapter *AddressBook.AddressBookAdapter
+	proxy   *AddressBook.AddressBookProxy
+}
+
+// newFixture exports impl at a path named after the test and connects a proxy to it, opts apply to both of them.
+// The adapter and the connection of the proxy are closed on cleanup of t
+func newFixture(t *testing.T, impl AddressBook.AddressBook, opts ...goqface.Option) *fixture {
+	t.Helper()
+	server, err := dbus.SessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := dbus.SessionBusPrivate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	if err = client.Auth(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = client.Hello(); err != nil {
+		t.Fatal(err)
+	}
+
+	objectPath := dbus.ObjectPath("/Tests/AddressBook/" + t.Name())
+	adapter := AddressBook.NewAddressBookAdapter(server, impl, append(opts, goqface.WithObjectPath(objectPath))...)
+	if err := adapter.Export(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(adapter.Close)
+
+	f := &fixture{server: server, client: client, adapter: adapter}
+	f.proxy = f.newProxy(t, opts...)
+	return f
+}
+
+// newProxy connects another proxy to the adapter of the fixture and fails t unless it is ready
+func (f *fixture) newProxy(t *testing.T, opts ...goqface.Option) *AddressBook.AddressBookProxy {
+	t.Helper()
+	proxy := AddressBook.NewAddressBookProxy(f.client, append(opts, goqface.WithObjectPath(f.adapter.ObjectPath()), goqface.WithServiceName(f.server.Names()[0]))...)
+	proxy.ConnectToRemoteObject()
+	t.Cleanup(proxy.Close)
+	if !proxy.Ready() {
+		t.Fatalf("proxy of %v not ready", f.adapter.ObjectPath())
+	}
+	return proxy
+}
+
+// remoteObject returns the adapter of the fixture as plain bus object
+func (f *fixture) remoteObject() dbus.BusObject {
+	return f.client.Object(f.server.Names()[0], f.adapter.ObjectPath())
+}
+
 func TestSetProperty(t *testing.T) {
 	var wg sync.WaitGroup
 
@@ -170,7 +265,9 @@ func TestSetProperty(t *testing.T) {
 	addressBookImpl := &AddressBookImpl{&AddressBook.AddressBookBase{}}
 	addressbookAdapter.Init(addressBookImpl)
 	defer addressbookAdapter.Close()
-	addressbookAdapter.Export()
+	if err := addressbookAdapter.Export(); err != nil {
+		t.Fatal(err)
+	}
 
 	addressBookServerObserver := &AddressBookServerObserver{wg: &wg}
 
@@ -236,7 +333,9 @@ func TestSetPropertyNotAllowed(t *testing.T) {
 	if addressBookImpl.CurrentContact().Idx != 2 {
 		t.Errorf("setCurrentContact failed to accept right value")
 	}
-	addressbookAdapter.Export()
+	if err := addressbookAdapter.Export(); err != nil {
+		t.Fatal(err)
+	}
 	defer addressbookAdapter.Close()
 
 	addressBookProxy := &AddressBook.AddressBookProxy{Conn: client}
@@ -275,7 +374,9 @@ func TestCallMethod(t *testing.T) {
 	addressbookAdapter := &AddressBook.AddressBookAdapter{Conn: server}
 	addressBookImpl := &AddressBookImpl{&AddressBook.AddressBookBase{}}
 	addressbookAdapter.Init(addressBookImpl)
-	addressbookAdapter.Export()
+	if err := addressbookAdapter.Export(); err != nil {
+		t.Fatal(err)
+	}
 	defer addressbookAdapter.Close()
 
 	addressBookProxy := &AddressBook.AddressBookProxy{Conn: client}
@@ -300,8 +401,15 @@ func TestCallMethod(t *testing.T) {
 	}
 	// intentionally select a non-existing index
 	errCallMethod = addressBookProxy.SelectContact(1)
-	if errCallMethod.Error() != dbus.ErrMsgInvalidArg.Error() {
-		t.Errorf("remote func didn't return error as expected! have %v expected %v", errCallMethod.Error(), dbus.ErrMsgInvalidArg.Error())
+	if !errors.Is(errCallMethod, AddressBook.ErrNotFound) {
+		t.Errorf("remote func didn't return error as expected! have %v expected %v", errCallMethod, AddressBook.ErrNotFound)
+	}
+	var domainErr *goqface.DomainError
+	if !errors.As(errCallMethod, &domainErr) || domainErr.Name != "Tests.AddressBook.Error.NotFound" || domainErr.Message != "no contact 1 to select" {
+		t.Errorf("remote error not decoded! have %#v", errCallMethod)
+	}
+	if _, errCallMethod = addressBookProxy.DeleteContact(1); !errors.Is(errCallMethod, AddressBook.ErrNotFound) {
+		t.Errorf("remote func didn't return error as expected! have %v expected %v", errCallMethod, AddressBook.ErrNotFound)
 	}
 
 	addressBookProxy.RemoveContactsChangedObserver(addressBookClient)
@@ -322,7 +430,9 @@ func TestSignal(t *testing.T) {
 	addressbookAdapter := &AddressBook.AddressBookAdapter{Conn: server}
 	addressBookImpl := &AddressBookImpl{&AddressBook.AddressBookBase{}}
 	addressbookAdapter.Init(addressBookImpl)
-	addressbookAdapter.Export()
+	if err := addressbookAdapter.Export(); err != nil {
+		t.Fatal(err)
+	}
 	defer addressbookAdapter.Close()
 
 	addressBookProxy := &AddressBook.AddressBookProxy{Conn: client}
@@ -358,7 +468,9 @@ func TestGetAllOnReadyChanged(t *testing.T) {
 	addressbookAdapter := &AddressBook.AddressBookAdapter{Conn: server}
 	addressBookImpl := &AddressBookImpl{&AddressBook.AddressBookBase{}}
 	addressbookAdapter.Init(addressBookImpl)
-	addressbookAdapter.Export()
+	if err := addressbookAdapter.Export(); err != nil {
+		t.Fatal(err)
+	}
 	defer addressbookAdapter.Close()
 	addressBookImpl.SetReady(true)
 	intValues := []int{1, 2, 3}
@@ -401,7 +513,9 @@ func TestObjectManager(t *testing.T) {
 	addressBookImpl := &AddressBookImpl{&AddressBook.AddressBookBase{}}
 	addressbookAdapter.Init(addressBookImpl)
 	addressbookAdapter.SetObjectPath(addressbookAdapter.ObjectPath() + "/ObjectManagement")
-	addressbookAdapter.Export()
+	if err := addressbookAdapter.Export(); err != nil {
+		t.Fatal(err)
+	}
 	addressBookImpl.SetReady(true)
 	intValues := []int{1, 2, 3}
 	debt := 3.141592653589793238
@@ -466,7 +580,9 @@ func TestServiceRemoved(t *testing.T) {
 	addressBookImpl := &AddressBookImpl{&AddressBook.AddressBookBase{}}
 	addressbookAdapter.Init(addressBookImpl)
 	addressbookAdapter.SetObjectPath(addressbookAdapter.ObjectPath() + "/ServiceRemoved")
-	addressbookAdapter.Export()
+	if err := addressbookAdapter.Export(); err != nil {
+		t.Fatal(err)
+	}
 	addressBookImpl.SetReady(true)
 
 	addressBookProxy := &AddressBook.AddressBookProxy{Conn: client}
@@ -534,13 +650,1235 @@ func TestIntrospect(t *testing.T) {
 	addressbookAdapter := &AddressBook.AddressBookAdapter{Conn: server}
 	addressBookImpl := &AddressBookImpl{&AddressBook.AddressBookBase{}}
 	addressbookAdapter.Init(addressBookImpl)
-	addressbookAdapter.Export()
+	if err := addressbookAdapter.Export(); err != nil {
+		t.Fatal(err)
+	}
 
 	introspect, err := introspect.Call(client.Object(addressbookServiceName, addressbookAdapter.ObjectPath()))
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(introspect.Interfaces[2].Properties) != 8 {
-		t.Fatalf("Unexpected number of props in introspection, expected %v have %v", 8, len(introspect.Interfaces[2].Properties))
+	if len(introspect.Interfaces[2].Properties) != 12 {
+		t.Fatalf("Unexpected number of props in introspection, expected %v have %v", 12, len(introspect.Interfaces[2].Properties))
+	}
+	docs := map[string]string{}
+	for _, annotation := range introspect.Interfaces[2].Annotations {
+		if annotation.Name == goqface.DocStringAnnotation {
+			docs[introspect.Interfaces[2].Name] = annotation.Value
+		}
+	}
+	for _, method := range introspect.Interfaces[2].Methods {
+		for _, annotation := range method.Annotations {
+			if annotation.Name == goqface.DocStringAnnotation {
+				docs[method.Name] = annotation.Value
+			}
+		}
+	}
+	for _, property := range introspect.Interfaces[2].Properties {
+		for _, annotation := range property.Annotations {
+			if annotation.Name == goqface.DocStringAnnotation {
+				docs[property.Name] = annotation.Value
+			}
+		}
+	}
+	for _, signal := range introspect.Interfaces[2].Signals {
+		for _, annotation := range signal.Annotations {
+			if annotation.Name == goqface.DocStringAnnotation {
+				docs[signal.Name] = annotation.Value
+			}
+		}
+	}
+	expectedDocs := map[string]string{
+		"Tests.AddressBook.AddressBook": "Keeps track of known contacts",
+		"createNewContact":              "Appends a new contact to the list of contacts",
+		"findContact":                   "Returns the first contact of the given name along with its index in contacts",
+		"contacts":                      "All known contacts",
+		"contactsByType":                "Contacts by their type",
+		"contactCreated":                "Emitted after a contact has been created",
+	}
+	if !reflect.DeepEqual(docs, expectedDocs) {
+		t.Errorf("Unexpected docs in introspection, expected %v have %v", expectedDocs, docs)
+	}
+	deprecated := []string{}
+	for _, method := range introspect.Interfaces[2].Methods {
+		for _, annotation := range method.Annotations {
+			if annotation.Name == goqface.DeprecatedAnnotation && annotation.Value == "true" {
+				deprecated = append(deprecated, method.Name)
+			}
+		}
+	}
+	for _, signal := range introspect.Interfaces[2].Signals {
+		for _, annotation := range signal.Annotations {
+			if annotation.Name == goqface.DeprecatedAnnotation && annotation.Value == "true" {
+				deprecated = append(deprecated, signal.Name)
+			}
+		}
+	}
+	if expected := []string{"selectContact", "contactUpdateFailed"}; !reflect.DeepEqual(deprecated, expected) {
+		t.Errorf("Unexpected deprecations in introspection, expected %v have %v", expected, deprecated)
+	}
+}
+
+func TestDefaultValues(t *testing.T) {
+	var wg sync.WaitGroup
+	server, err := dbus.SessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := dbus.SessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	if !addressBookImpl.Ready() || !addressBookImpl.IsLoaded() {
+		t.Errorf("default values of bool properties not applied")
+	}
+	if !reflect.DeepEqual(addressBookImpl.IntValues(), []int{7, 8}) {
+		t.Errorf("default value of list property not applied! have %v want %v", addressBookImpl.IntValues(), []int{7, 8})
+	}
+	expectedContact := AddressBook.Contact{Idx: -1, Type: AddressBook.Family}
+	if !reflect.DeepEqual(addressBookImpl.CurrentContact(), expectedContact) {
+		t.Errorf("default value of struct property not applied! have %v want %v", addressBookImpl.CurrentContact(), expectedContact)
+	}
+	if !reflect.DeepEqual(AddressBook.NewNested().ListOfContact, expectedContact) {
+		t.Errorf("default value of nested struct field not applied! have %v want %v", AddressBook.NewNested().ListOfContact, expectedContact)
+	}
+
+	addressbookAdapter := &AddressBook.AddressBookAdapter{Conn: server}
+	addressbookAdapter.Init(addressBookImpl)
+	addressbookAdapter.SetObjectPath(addressbookAdapter.ObjectPath() + "/DefaultValues")
+	if err := addressbookAdapter.Export(); err != nil {
+		t.Fatal(err)
+	}
+	defer addressbookAdapter.Close()
+
+	addressBookProxy := &AddressBook.AddressBookProxy{Conn: client}
+	addressBookProxy.Init()
+	addressBookProxy.SetObjectPath(addressBookProxy.ObjectPath() + "/DefaultValues")
+	addressBookProxy.SetServiceName(server.Names()[0])
+	if !addressBookProxy.IsLoaded() || !reflect.DeepEqual(addressBookProxy.CurrentContact(), expectedContact) {
+		t.Errorf("default values not applied on proxy before connecting to remote object")
+	}
+
+	addressBookClient := &AddressBookClient{wg: &wg}
+	addressBookProxy.AddReadyChangedObserver(addressBookClient)
+	wg.Add(1)
+	addressBookProxy.ConnectToRemoteObject()
+	if waitTimeout(&wg, time.Second) {
+		t.Errorf("Timed out waiting for wait group")
+	}
+	addressBookProxy.RemoveReadyChangedObserver(addressBookClient)
+	if !reflect.DeepEqual(addressBookProxy.IntValues(), []int{7, 8}) {
+		t.Errorf("default value not published by adapter! have %v want %v", addressBookProxy.IntValues(), []int{7, 8})
+	}
+}
+
+func TestExport(t *testing.T) {
+	f := newFixture(t, &AddressBookImpl{AddressBook.NewAddressBookBase()})
+	movedPath := f.adapter.ObjectPath() + "/Moved"
+	if err := f.adapter.SetObjectPath(movedPath); err == nil {
+		t.Errorf("object path of exported adapter changed")
+	}
+	f.adapter.Close()
+	if err := f.adapter.SetObjectPath(movedPath); err != nil {
+		t.Errorf("object path of closed adapter not changed: %v", err)
+	}
+
+	invalidAdapter := AddressBook.NewAddressBookAdapter(f.server, &AddressBookImpl{AddressBook.NewAddressBookBase()}, goqface.WithObjectPath("Invalid"))
+	if err := goqface.NewServer(f.server).Export(invalidAdapter); err == nil {
+		t.Errorf("adapter exported at invalid path %v", invalidAdapter.ObjectPath())
+	}
+}
+
+func TestServer(t *testing.T) {
+	var wg sync.WaitGroup
+	conn, err := dbus.SessionBusPrivate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.Auth(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.Hello(); err != nil {
+		t.Fatal(err)
+	}
+	client, err := dbus.SessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addressbookServiceName := "addressbook.server"
+	objectPath := dbus.ObjectPath("/Tests/AddressBook/Server")
+
+	server := goqface.NewServer(conn)
+	addressbookAdapter := AddressBook.NewAddressBookAdapter(conn, &AddressBookImpl{AddressBook.NewAddressBookBase()},
+		goqface.WithObjectPath(objectPath), goqface.WithServiceName(addressbookServiceName))
+	if err := server.Export(addressbookAdapter); err != nil {
+		t.Fatal(err)
+	}
+
+	addressBookProxy := AddressBook.NewAddressBookProxy(client, goqface.WithObjectPath(objectPath), goqface.WithServiceName(addressbookServiceName))
+	addressBookClient := &AddressBookClient{wg: &wg}
+	addressBookProxy.AddReadyChangedObserver(addressBookClient)
+	wg.Add(1)
+	addressBookProxy.ConnectToRemoteObject()
+	if waitTimeout(&wg, time.Second) {
+		t.Errorf("Timed out waiting for wait group")
+	}
+	addressBookProxy.RemoveReadyChangedObserver(addressBookClient)
+	if !addressBookProxy.Ready() {
+		t.Errorf("proxy not connected to adapter exported by server!")
+	}
+
+	rival, err := dbus.SessionBusPrivate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = rival.Auth(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = rival.Hello(); err != nil {
+		t.Fatal(err)
+	}
+	rivalServer := goqface.NewServer(rival)
+	rivalPath := objectPath + "/Rival"
+	rivalAdapter := AddressBook.NewAddressBookAdapter(rival, &AddressBookImpl{AddressBook.NewAddressBookBase()},
+		goqface.WithObjectPath(rivalPath), goqface.WithServiceName(addressbookServiceName))
+	if err := rivalServer.Export(rivalAdapter); err == nil {
+		t.Errorf("adapter exported under bus name %v owned by another server", addressbookServiceName)
+	}
+	if _, err := client.Object(rival.Names()[0], rivalPath).GetProperty("Tests.AddressBook.AddressBook.debt"); err == nil {
+		t.Errorf("adapter left exported after bus name %v was denied", addressbookServiceName)
+	}
+	if err := rivalServer.Close(); err != nil {
+		t.Errorf("server failed to shut down: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- server.Run(ctx)
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("server failed to shut down: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for server to shut down")
+	}
+
+	var hasOwner bool
+	if err := client.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, addressbookServiceName).Store(&hasOwner); err != nil {
+		t.Fatal(err)
+	}
+	if hasOwner {
+		t.Errorf("bus name %v not released on server shut down", addressbookServiceName)
+	}
+}
+
+func TestSubscription(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookProxy := newFixture(t, addressBookImpl).proxy
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	baseDebts := addressBookImpl.SubscribeDebtChanged(ctx)
+	proxyDebts := addressBookProxy.SubscribeDebtChanged(ctx)
+	created := addressBookProxy.SubscribeContactCreated(ctx)
+
+	debts := []float64{1, 2, 3}
+	for _, debt := range debts {
+		addressBookImpl.SetDebt(debt)
+	}
+	for _, subscription := range []<-chan float64{baseDebts, proxyDebts} {
+		for _, expected := range debts {
+			select {
+			case value := <-subscription:
+				if value != expected {
+					t.Errorf("debt changes out of order! have %v want %v", value, expected)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Timed out waiting for debt change")
+			}
+		}
+	}
+
+	if err := addressBookProxy.CreateNewContact(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-created:
+		if !reflect.DeepEqual(event.Contact, addressBookImpl.Contacts()[len(addressBookImpl.Contacts())-1]) {
+			t.Errorf("unexpected contact created %v", event.Contact)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for contactCreated")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-created:
+		if ok {
+			t.Errorf("subscription not closed after context is done")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for subscription to be closed")
+	}
+}
+
+func TestSubscriptionBufferSize(t *testing.T) {
+	var feed goqface.Feed[int]
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocking := feed.Subscribe(ctx, goqface.WithBufferSize(-1))
+	dropping := feed.Subscribe(ctx, goqface.WithBufferSize(-1), goqface.WithDropPolicy(goqface.DropOldest))
+	if cap(blocking) != 0 || cap(dropping) != 0 {
+		t.Errorf("negative buffer size not treated as unbuffered %v, %v", cap(blocking), cap(dropping))
+	}
+
+	sent := make(chan struct{})
+	go func() {
+		feed.Send(1)
+		close(sent)
+	}()
+	select {
+	case value := <-blocking:
+		if value != 1 {
+			t.Errorf("unexpected value %v", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for value")
+	}
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for send to the unbuffered dropping subscription")
+	}
+	select {
+	case value := <-dropping:
+		t.Errorf("value %v not dropped without a receiver", value)
+	default:
+	}
+}
+
+func TestCallbackObservers(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookProxy := newFixture(t, addressBookImpl).proxy
+
+	debts := make(chan float64, 4)
+	unsubscribe := addressBookProxy.OnDebtChanged(func(debt float64) {
+		debts <- debt
+	})
+	created := make(chan AddressBook.Contact, 1)
+	defer addressBookProxy.OnContactCreated(func(contact AddressBook.Contact) {
+		created <- contact
+	})()
+
+	addressBookImpl.SetDebt(1)
+	select {
+	case value := <-debts:
+		if value != 1 {
+			t.Errorf("unexpected debt %v", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for debt change")
+	}
+
+	if err := addressBookProxy.CreateNewContact(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-created:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for contactCreated")
+	}
+
+	unsubscribe()
+	unsubscribe()
+	// wait for the change to arrive over a channel subscribed after unsubscribing
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := addressBookProxy.SubscribeDebtChanged(ctx)
+	addressBookImpl.SetDebt(2)
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for debt change")
+	}
+	select {
+	case value := <-debts:
+		t.Errorf("callback called after unsubscribe with %v", value)
+	default:
+	}
+
+	var wg sync.WaitGroup
+	observer := &AddressBookClient{wg: &wg}
+	addressBookProxy.AddContactsChangedObserver(observer)
+	addressBookProxy.AddContactsChangedObserver(observer)
+	if !addressBookProxy.RemoveContactsChangedObserver(observer) {
+		t.Errorf("observer not registered")
+	}
+	if addressBookProxy.RemoveContactsChangedObserver(observer) {
+		t.Errorf("observer registered more than once")
+	}
+}
+
+func TestChangedFrom(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookProxy := newFixture(t, addressBookImpl).proxy
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	proxyChanges := addressBookProxy.SubscribeDebtChangedFrom(ctx)
+	baseChanges := make(chan goqface.PropertyChange[float64], 4)
+	defer addressBookImpl.OnDebtChangedFrom(func(old, new float64) {
+		baseChanges <- goqface.PropertyChange[float64]{Old: old, New: new}
+	})()
+
+	addressBookImpl.SetDebt(1)
+	addressBookImpl.SetDebt(2)
+	expected := []goqface.PropertyChange[float64]{{Old: 0, New: 1}, {Old: 1, New: 2}}
+	for _, changes := range []<-chan goqface.PropertyChange[float64]{baseChanges, proxyChanges} {
+		for _, change := range expected {
+			select {
+			case value := <-changes:
+				if value != change {
+					t.Errorf("unexpected debt change! have %v want %v", value, change)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Timed out waiting for debt change")
+			}
+		}
+	}
+}
+
+func TestBatch(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookProxy := newFixture(t, addressBookImpl).proxy
+
+	contacts := []AddressBook.Contact{{Idx: 1, Name: "Name1"}, {Idx: 2, Name: "Name2"}}
+	type snapshot struct {
+		changed  map[string]interface{}
+		contacts []AddressBook.Contact
+		current  AddressBook.Contact
+		debt     float64
+	}
+	snapshots := make(chan snapshot, 4)
+	defer addressBookProxy.OnPropertiesChanged(func(changed map[string]interface{}) {
+		snapshots <- snapshot{changed, addressBookProxy.Contacts(), addressBookProxy.CurrentContact(), addressBookProxy.Debt()}
+	})()
+
+	err := addressBookImpl.Batch(func(tx *AddressBook.AddressBookTx) error {
+		tx.SetContacts(contacts)
+		tx.SetCurrentContact(contacts[1])
+		tx.SetDebt(tx.Debt() + 10)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addressBookImpl.Debt() != 10 || !reflect.DeepEqual(addressBookImpl.CurrentContact(), contacts[1]) {
+		t.Errorf("transaction not applied")
+	}
+
+	select {
+	case s := <-snapshots:
+		if len(s.changed) != 3 {
+			t.Errorf("changes not published at once %v", s.changed)
+		}
+		if !reflect.DeepEqual(s.contacts, contacts) || !reflect.DeepEqual(s.current, contacts[1]) || s.debt != 10 {
+			t.Errorf("inconsistent state observed %v", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for properties changed")
+	}
+
+	errInvalid := errors.New("invalid")
+	err = addressBookImpl.Batch(func(tx *AddressBook.AddressBookTx) error {
+		tx.SetDebt(20)
+		return errInvalid
+	})
+	if !errors.Is(err, errInvalid) || addressBookImpl.Debt() != 10 {
+		t.Errorf("aborted transaction applied debt %v: %v", addressBookImpl.Debt(), err)
+	}
+	tx := addressBookImpl.BeginUpdate()
+	tx.SetContacts(nil)
+	tx.SetDebt(-1)
+	if err := tx.Commit(); !errors.Is(err, goqface.ErrInvalidArgs) {
+		t.Errorf("transaction violating constraints committed: %v", err)
+	}
+	if addressBookImpl.Debt() != 10 || !reflect.DeepEqual(addressBookImpl.Contacts(), contacts) {
+		t.Errorf("transaction violating constraints partially applied")
+	}
+	tx.SetDebt(10)
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	addressBookImpl.SetDebt(11)
+	select {
+	case s := <-snapshots:
+		if !reflect.DeepEqual(s.changed, map[string]interface{}{"debt": float64(11)}) {
+			t.Errorf("unexpected changes %v", s.changed)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for properties changed")
+	}
+}
+
+func TestInvalidation(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	f := newFixture(t, addressBookImpl)
+	addressBookProxy := f.proxy
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	debts := addressBookProxy.SubscribeDebtChanged(ctx)
+
+	contacts := map[string]AddressBook.Contact{"first": {Idx: 1, Name: "Name1"}}
+	addressBookImpl.SetMapOfContacts(contacts)
+	// the debt change is received after the invalidation of mapOfContacts
+	addressBookImpl.SetDebt(1)
+	select {
+	case <-debts:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for debt change")
+	}
+	if !reflect.DeepEqual(addressBookProxy.MapOfContacts(), contacts) {
+		t.Errorf("invalidated property not fetched on access! have %v want %v", addressBookProxy.MapOfContacts(), contacts)
+	}
+
+	// observed properties are fetched right away
+	mapOfContacts := addressBookProxy.SubscribeMapOfContactsChanged(ctx)
+	addressBookImpl.SetMapOfContacts(map[string]AddressBook.Contact{})
+	select {
+	case value := <-mapOfContacts:
+		if len(value) != 0 {
+			t.Errorf("unexpected change of mapOfContacts %v", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for observed mapOfContacts to be fetched")
+	}
+
+	addressBookImpl.SetMapOfContacts(contacts)
+	if err := addressBookProxy.RefreshMapOfContacts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(addressBookProxy.MapOfContacts(), contacts) {
+		t.Errorf("property not refreshed! have %v want %v", addressBookProxy.MapOfContacts(), contacts)
+	}
+
+	node, err := introspect.Call(f.remoteObject())
+	if err != nil {
+		t.Fatal(err)
+	}
+	emits := map[string]string{}
+	for _, property := range node.Interfaces[2].Properties {
+		for _, annotation := range property.Annotations {
+			if annotation.Name == goqface.EmitsChangedSignalAnnotation {
+				emits[property.Name] = annotation.Value
+			}
+		}
+	}
+	if emits["mapOfContacts"] != "invalidates" || emits["contacts"] != "true" {
+		t.Errorf("unexpected EmitsChangedSignal annotations %v", emits)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	f := newFixture(t, addressBookImpl, goqface.WithRefreshInterval(50*time.Millisecond))
+	addressBookProxy := f.proxy
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	debts := addressBookProxy.SubscribeDebtChanged(ctx)
+
+	// miss all further changes of properties
+	err := f.client.RemoveMatchSignal(dbus.WithMatchInterface("org.freedesktop.DBus.Properties"), dbus.WithMatchMember("PropertiesChanged"),
+		dbus.WithMatchObjectPath(f.adapter.ObjectPath()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addressBookImpl.SetDebt(5)
+	if err := addressBookProxy.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if addressBookProxy.Debt() != 5 {
+		t.Errorf("property not refreshed! have %v want %v", addressBookProxy.Debt(), 5)
+	}
+	select {
+	case value := <-debts:
+		if value != 5 {
+			t.Errorf("unexpected debt change %v", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for debt change")
+	}
+
+	addressBookImpl.SetDebt(6)
+	select {
+	case value := <-debts:
+		if value != 6 {
+			t.Errorf("unexpected debt change %v", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for periodic refresh")
+	}
+
+	addressBookProxy.Close()
+	// let a refresh in progress finish
+	time.Sleep(100 * time.Millisecond)
+	addressBookImpl.SetDebt(7)
+	select {
+	case value := <-debts:
+		t.Errorf("refreshed debt %v after Close", value)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if addressBookProxy.Debt() != 6 {
+		t.Errorf("property refreshed after Close! have %v want %v", addressBookProxy.Debt(), 6)
+	}
+}
+
+func TestSetMode(t *testing.T) {
+	f := newFixture(t, &AddressBookImpl{AddressBook.NewAddressBookBase()}, goqface.WithSetMode(goqface.SetConfirmed))
+	confirmedProxy := f.proxy
+	if err := confirmedProxy.SetDebt(3); err != nil {
+		t.Fatal(err)
+	}
+	if confirmedProxy.Debt() != 3 {
+		t.Errorf("confirmed set not applied to proxy! have %v want %v", confirmedProxy.Debt(), 3)
+	}
+
+	// confirmation doesn't wait for the notification of observers, which would block setters called by observers
+	confirmedContact := AddressBook.Contact{Idx: 5, Name: "Name5"}
+	setByObserver := make(chan error, 1)
+	unsubscribe := confirmedProxy.OnDebtChanged(func(debt float64) {
+		if debt == 5 {
+			setByObserver <- confirmedProxy.SetCurrentContact(confirmedContact)
+		}
+	})
+	start := time.Now()
+	if err := confirmedProxy.SetDebt(5); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-setByObserver:
+		if err != nil {
+			t.Errorf("confirmed set by observer failed %v", err)
+		}
+		if elapsed := time.Since(start); elapsed >= goqface.DefaultSetTimeout {
+			t.Errorf("confirmed set by observer waited %v for the timeout", elapsed)
+		}
+	case <-time.After(2 * goqface.DefaultSetTimeout):
+		t.Fatalf("Timed out waiting for confirmed set by observer")
+	}
+	unsubscribe()
+	if !reflect.DeepEqual(confirmedProxy.CurrentContact(), confirmedContact) {
+		t.Errorf("confirmed set by observer not applied! have %v want %v", confirmedProxy.CurrentContact(), confirmedContact)
+	}
+
+	optimisticProxy := f.newProxy(t, goqface.WithSetMode(goqface.SetOptimistic))
+	contacts := make(chan AddressBook.Contact, 4)
+	defer optimisticProxy.OnCurrentContactChanged(func(contact AddressBook.Contact) {
+		contacts <- contact
+	})()
+	current := optimisticProxy.CurrentContact()
+	rejected := AddressBook.Contact{Idx: -1, Name: "Rejected"}
+	if err := optimisticProxy.SetCurrentContact(rejected); err == nil {
+		t.Errorf("invalid contact accepted by remote object")
+	}
+	if !reflect.DeepEqual(optimisticProxy.CurrentContact(), current) {
+		t.Errorf("rejected value not rolled back! have %v want %v", optimisticProxy.CurrentContact(), current)
+	}
+	for _, expected := range []AddressBook.Contact{rejected, current} {
+		select {
+		case contact := <-contacts:
+			if !reflect.DeepEqual(contact, expected) {
+				t.Errorf("unexpected current contact change! have %v want %v", contact, expected)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for current contact change")
+		}
+	}
+
+	if err := optimisticProxy.SetDebt(4); err != nil {
+		t.Fatal(err)
+	}
+	if optimisticProxy.Debt() != 4 {
+		t.Errorf("optimistic set not applied to proxy! have %v want %v", optimisticProxy.Debt(), 4)
+	}
+}
+
+func TestValidator(t *testing.T) {
+	server, err := dbus.SessionBus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addressBookImpl := &ValidatingAddressBookImpl{AddressBookImpl: &AddressBookImpl{AddressBook.NewAddressBookBase()}, writer: dbus.Sender(server.Names()[0])}
+	f := newFixture(t, addressBookImpl)
+	addressBookProxy := f.proxy
+
+	var dbusErr dbus.Error
+	err = addressBookProxy.SetContacts([]AddressBook.Contact{{Idx: 1}})
+	if !errors.As(err, &dbusErr) || dbusErr.Name != goqface.InvalidArgsErrorName {
+		t.Errorf("invalid contacts not rejected with %v: %v", goqface.InvalidArgsErrorName, err)
+	}
+	if len(addressBookImpl.Contacts()) != 0 {
+		t.Errorf("rejected contacts applied %v", addressBookImpl.Contacts())
+	}
+	contacts := []AddressBook.Contact{{Idx: 1, Name: "Name1"}}
+	if err := addressBookProxy.SetContacts(contacts); err != nil {
+		t.Errorf("valid contacts rejected %v", err)
+	}
+	if !reflect.DeepEqual(addressBookImpl.Contacts(), contacts) {
+		t.Errorf("valid contacts not applied! have %v want %v", addressBookImpl.Contacts(), contacts)
+	}
+
+	err = addressBookProxy.SetDebt(1)
+	if !errors.As(err, &dbusErr) || dbusErr.Name != goqface.AccessDeniedErrorName {
+		t.Errorf("debt written by %v not denied with %v: %v", f.client.Names()[0], goqface.AccessDeniedErrorName, err)
+	}
+	if addressBookImpl.Debt() != 0 {
+		t.Errorf("denied debt applied %v", addressBookImpl.Debt())
+	}
+}
+
+func TestRejectedWrites(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookImpl.SetDebt(1.5)
+	remoteObj := newFixture(t, addressBookImpl).remoteObject()
+	rejected := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"debt", "1.5", goqface.InvalidArgsErrorName},
+		{"debt", int32(2), goqface.InvalidArgsErrorName},
+		{"currentContact", AddressBook.Contact{Idx: -1}, goqface.FailedErrorName},
+		{"mapOfContacts", map[string]AddressBook.Contact{}, goqface.PropertyReadOnlyErrorName},
+		{"unknown", 1, goqface.UnknownPropertyErrorName},
+	}
+	var dbusErr dbus.Error
+	for _, write := range rejected {
+		err := remoteObj.SetProperty("Tests.AddressBook.AddressBook."+write.name, dbus.MakeVariant(write.value))
+		if !errors.As(err, &dbusErr) || dbusErr.Name != write.want {
+			t.Errorf("write of %v to %v not rejected with %v: %v", write.value, write.name, write.want, err)
+		}
+	}
+	err := remoteObj.Call("org.freedesktop.DBus.Properties.Set", 0, "Tests.AddressBook.Unknown", "debt", dbus.MakeVariant(2.5)).Err
+	if !errors.As(err, &dbusErr) || dbusErr.Name != goqface.UnknownInterfaceErrorName {
+		t.Errorf("write to unknown interface not rejected with %v: %v", goqface.UnknownInterfaceErrorName, err)
+	}
+
+	if err := addressBookImpl.SetCurrentContact(AddressBook.Contact{Idx: -1}); err == nil {
+		t.Errorf("setCurrentContact accepted wrong value")
+	}
+	debt, err := remoteObj.GetProperty("Tests.AddressBook.AddressBook.debt")
+	if err != nil || debt.Value() != addressBookImpl.Debt() {
+		t.Errorf("remote debt %v disagrees with %v after rejected writes: %v", debt, addressBookImpl.Debt(), err)
+	}
+	var currentContact AddressBook.Contact
+	if err := remoteObj.StoreProperty("Tests.AddressBook.AddressBook.currentContact", &currentContact); err != nil || currentContact != addressBookImpl.CurrentContact() {
+		t.Errorf("remote currentContact %v disagrees with %v after rejected writes: %v", currentContact, addressBookImpl.CurrentContact(), err)
+	}
+}
+
+func TestCaller(t *testing.T) {
+	addressBookImpl := &AuditingAddressBookImpl{AddressBookImpl: &AddressBookImpl{AddressBook.NewAddressBookBase()}}
+	addressBookImpl.SetContacts([]AddressBook.Contact{{Idx: 1, Name: "Name1"}})
+	f := newFixture(t, addressBookImpl)
+	addressBookProxy := f.proxy
+
+	if _, err := addressBookProxy.DeleteContact(1); err != nil {
+		t.Fatalf("call to remote object failed! %v", err)
+	}
+	if len(addressBookImpl.deletedBy) != 1 {
+		t.Fatalf("DeleteContactWithCaller not called instead of DeleteContact")
+	}
+	credentials := addressBookImpl.deletedBy[0]
+	if credentials.UnixUserID != uint32(os.Getuid()) {
+		t.Errorf("caller uid mismatch! have %v want %v", credentials.UnixUserID, os.Getuid())
+	}
+	if credentials.ProcessID != 0 && credentials.ProcessID != uint32(os.Getpid()) {
+		t.Errorf("caller pid mismatch! have %v want %v", credentials.ProcessID, os.Getpid())
+	}
+	if len(addressBookImpl.Contacts()) != 0 {
+		t.Errorf("contact not deleted %v", addressBookImpl.Contacts())
+	}
+
+	if _, err := (goqface.Caller{Sender: dbus.Sender(f.client.Names()[0])}).Credentials(context.Background()); !errors.Is(err, goqface.ErrNotConnected) {
+		t.Errorf("credentials resolved without connection: %v", err)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	contact := AddressBook.Contact{Idx: 0, Name: "Renamed"}
+
+	t.Run("Rules", func(t *testing.T) {
+		addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+		addressBookImpl.SetContacts([]AddressBook.Contact{{Idx: 0, Name: "Name0"}})
+		addressBookProxy := newFixture(t, addressBookImpl).proxy
+
+		// updateContact is restricted to root or members of addressbook
+		allowed := os.Getuid() == 0
+		if group, err := user.LookupGroup("addressbook"); err == nil && !allowed {
+			gid, err := strconv.Atoi(group.Gid)
+			if err != nil {
+				t.Skipf("gid %v of group addressbook unknown: %v", group.Gid, err)
+			}
+			gids, err := os.Getgroups()
+			if err != nil {
+				t.Skipf("membership of group addressbook unknown: %v", err)
+			}
+			for _, id := range append(gids, os.Getgid()) {
+				allowed = allowed || id == gid
+			}
+		}
+		var dbusErr dbus.Error
+		err := addressBookProxy.UpdateContact(0, contact)
+		if allowed {
+			if err != nil {
+				t.Errorf("updateContact denied to root or member of addressbook: %v", err)
+			}
+		} else if !errors.As(err, &dbusErr) || dbusErr.Name != goqface.AccessDeniedErrorName {
+			t.Errorf("updateContact not denied with %v: %v", goqface.AccessDeniedErrorName, err)
+		}
+		if err = addressBookProxy.SetDebt(1); err != nil {
+			t.Errorf("write of unrestricted debt denied: %v", err)
+		}
+	})
+
+	t.Run("Policy", func(t *testing.T) {
+		var requests []goqface.AccessRequest
+		policy := goqface.PolicyFunc(func(ctx context.Context, request goqface.AccessRequest) error {
+			requests = append(requests, request)
+			if request.Write {
+				return fmt.Errorf("%s may not write %s", request.Caller.Sender, request.Member)
+			}
+			return nil
+		})
+		addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+		addressBookImpl.SetContacts([]AddressBook.Contact{{Idx: 0, Name: "Name0"}})
+		f := newFixture(t, addressBookImpl, goqface.WithPolicy(policy))
+		addressBookProxy := f.proxy
+
+		var dbusErr dbus.Error
+		if err := addressBookProxy.UpdateContact(0, contact); err != nil {
+			t.Errorf("updateContact denied by policy: %v", err)
+		}
+		err := addressBookProxy.SetDebt(2)
+		if !errors.As(err, &dbusErr) || dbusErr.Name != goqface.AccessDeniedErrorName {
+			t.Errorf("write of debt not denied with %v: %v", goqface.AccessDeniedErrorName, err)
+		}
+		if addressBookImpl.Debt() != 0 {
+			t.Errorf("denied debt applied %v", addressBookImpl.Debt())
+		}
+		want := []goqface.AccessRequest{
+			{Interface: "Tests.AddressBook.AddressBook", Member: "updateContact", Rules: []string{"uid=0", "group=addressbook"}},
+			{Interface: "Tests.AddressBook.AddressBook", Member: "debt", Write: true},
+		}
+		if len(requests) != len(want) {
+			t.Fatalf("policy asked %d times, want %d", len(requests), len(want))
+		}
+		for i, request := range requests {
+			if request.Caller.Sender != dbus.Sender(f.client.Names()[0]) {
+				t.Errorf("request of %v instead of %v", request.Caller.Sender, f.client.Names()[0])
+			}
+			request.Caller = goqface.Caller{}
+			if !reflect.DeepEqual(request, want[i]) {
+				t.Errorf("request mismatch! have %v want %v", request, want[i])
+			}
+		}
+	})
+}
+
+func TestMultipleReturnValues(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	contacts := []AddressBook.Contact{{Idx: 3, Name: "Name3"}, {Idx: 5, Name: "Name5"}}
+	addressBookImpl.SetContacts(contacts)
+	f := newFixture(t, addressBookImpl)
+	addressBookProxy := f.proxy
+
+	contact, index, err := addressBookProxy.FindContact("Name5")
+	if err != nil {
+		t.Fatalf("call to remote object failed! %v", err)
+	}
+	if !reflect.DeepEqual(contact, contacts[1]) || index != 1 {
+		t.Errorf("out args mismatch! have %v, %v want %v, %v", contact, index, contacts[1], 1)
+	}
+	if _, _, err = addressBookProxy.FindContact("Name4"); !errors.Is(err, AddressBook.ErrNotFound) {
+		t.Errorf("remote func didn't return error as expected! have %v expected %v", err, AddressBook.ErrNotFound)
+	}
+
+	node, err := introspect.Call(f.remoteObject())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outArgs []string
+	for _, method := range node.Interfaces[2].Methods {
+		if method.Name == "findContact" {
+			for _, arg := range method.Args {
+				if arg.Direction == "out" {
+					outArgs = append(outArgs, arg.Type)
+				}
+			}
+		}
+	}
+	if want := []string{"(issi)", "i"}; !reflect.DeepEqual(outArgs, want) {
+		t.Errorf("out args of findContact mismatch in introspection! have %v want %v", outArgs, want)
+	}
+}
+
+func TestNestedTypes(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	f := newFixture(t, addressBookImpl, goqface.WithSetMode(goqface.SetConfirmed))
+	addressBookProxy := f.proxy
+
+	contactsByType := map[int][]AddressBook.Contact{
+		int(AddressBook.Family):    {{Idx: 1, Name: "Name1", Type: AddressBook.Family}},
+		int(AddressBook.Colleague): {{Idx: 2, Name: "Name2", Type: AddressBook.Colleague}, {Idx: 3, Name: "Name3", Type: AddressBook.Colleague}},
+	}
+	if err := addressBookProxy.SetContactsByType(contactsByType); err != nil {
+		t.Fatalf("setContactsByType failed %v", err)
+	}
+	if !reflect.DeepEqual(addressBookImpl.ContactsByType(), contactsByType) {
+		t.Errorf("Object value mismatch! have %v want %v", addressBookImpl.ContactsByType(), contactsByType)
+	}
+	if !reflect.DeepEqual(addressBookProxy.ContactsByType(), contactsByType) {
+		t.Errorf("Object value mismatch! have %v want %v", addressBookProxy.ContactsByType(), contactsByType)
+	}
+
+	node, err := introspect.Call(f.remoteObject())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, property := range node.Interfaces[2].Properties {
+		if property.Name == "contactsByType" && property.Type != "a{ia(issi)}" {
+			t.Errorf("contactsByType signature mismatch! have %v want %v", property.Type, "a{ia(issi)}")
+		}
+	}
+}
+
+func TestVariants(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookProxy := newFixture(t, addressBookImpl).proxy
+
+	settingsChanged := make(chan map[string]dbus.Variant, 1)
+	addressBookProxy.OnSettingsChanged(func(settings map[string]dbus.Variant) {
+		settingsChanged <- settings
+	})
+	contact := AddressBook.Contact{Idx: 1, Name: "Name1", Type: AddressBook.Friend}
+	addressBookImpl.SetSettings(map[string]dbus.Variant{
+		"volume":  dbus.MakeVariant(7),
+		"name":    dbus.MakeVariant("Name"),
+		"contact": dbus.MakeVariant(contact),
+	})
+	var settings map[string]dbus.Variant
+	select {
+	case settings = <-settingsChanged:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for settings")
+	}
+	if volume, ok := goqface.Lookup[int](settings, "volume"); !ok || volume != 7 {
+		t.Errorf("volume mismatch! have %v want %v", settings["volume"], 7)
+	}
+	if name, ok := goqface.Lookup[string](settings, "name"); !ok || name != "Name" {
+		t.Errorf("name mismatch! have %v want %v", settings["name"], "Name")
+	}
+	if _, ok := goqface.Lookup[string](settings, "volume"); ok {
+		t.Errorf("volume looked up as string")
+	}
+	if received, err := goqface.VariantAs[AddressBook.Contact](settings["contact"]); err != nil || received != contact {
+		t.Errorf("contact mismatch! have %v want %v: %v", received, contact, err)
+	}
+
+	if err := addressBookProxy.SetLastSetting(dbus.MakeVariant("volume")); err != nil {
+		t.Fatalf("setLastSetting failed %v", err)
+	}
+	if lastSetting := addressBookImpl.LastSetting(); lastSetting.Value() != "volume" {
+		t.Errorf("lastSetting mismatch! have %v want %v", lastSetting, "volume")
+	}
+	if err := addressBookProxy.RefreshLastSetting(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if lastSetting, err := goqface.VariantAs[string](addressBookProxy.LastSetting()); err != nil || lastSetting != "volume" {
+		t.Errorf("lastSetting mismatch! have %v want %v: %v", addressBookProxy.LastSetting(), "volume", err)
+	}
+}
+
+func TestEnums(t *testing.T) {
+	if AddressBook.Colleague.String() != "Colleague" || AddressBook.ContactType(7).String() != "ContactType(7)" {
+		t.Errorf("unexpected names %v, %v", AddressBook.Colleague, AddressBook.ContactType(7))
+	}
+	if !AddressBook.Friend.IsValid() || AddressBook.ContactType(7).IsValid() {
+		t.Errorf("unexpected validity")
+	}
+	if contactType, err := AddressBook.ParseContactType("Family"); err != nil || contactType != AddressBook.Family {
+		t.Errorf("ParseContactType mismatch! have %v want %v: %v", contactType, AddressBook.Family, err)
+	}
+	if _, err := AddressBook.ParseContactType("Enemy"); err == nil {
+		t.Errorf("ParseContactType accepted Enemy")
+	}
+	data, err := json.Marshal(map[string]AddressBook.ContactType{"type": AddressBook.Colleague})
+	if err != nil || string(data) != `{"type":"Colleague"}` {
+		t.Errorf("unexpected json %s: %v", data, err)
+	}
+	var decoded map[string]AddressBook.ContactType
+	if err := json.Unmarshal([]byte(`{"type":"Friend"}`), &decoded); err != nil || decoded["type"] != AddressBook.Friend {
+		t.Errorf("unexpected decoded json %v: %v", decoded, err)
+	}
+	if _, err := json.Marshal(AddressBook.ContactType(7)); err == nil {
+		t.Errorf("invalid enum marshaled")
+	}
+
+	permission := AddressBook.Read | AddressBook.Share
+	if permission.String() != "Read|Share" || AddressBook.Permission(9).String() != "Read|Permission(8)" {
+		t.Errorf("unexpected names of flags %v, %v", permission, AddressBook.Permission(9))
+	}
+	if !permission.IsValid() || !AddressBook.Permission(0).IsValid() || AddressBook.Permission(8).IsValid() {
+		t.Errorf("unexpected validity of flags")
+	}
+	if parsed, err := AddressBook.ParsePermission("Read|Share"); err != nil || parsed != permission {
+		t.Errorf("ParsePermission mismatch! have %v want %v: %v", parsed, permission, err)
+	}
+	if _, err := AddressBook.ParsePermission("Read|Delete"); err == nil {
+		t.Errorf("ParsePermission accepted Delete")
+	}
+	data, err = json.Marshal(map[string]AddressBook.Permission{"none": 0, "all": permission})
+	if err != nil || string(data) != `{"all":"Read|Share","none":""}` {
+		t.Errorf("unexpected json of flags %s: %v", data, err)
+	}
+	var decodedPermissions map[string]AddressBook.Permission
+	if err := json.Unmarshal(data, &decodedPermissions); err != nil || decodedPermissions["none"] != 0 || decodedPermissions["all"] != permission {
+		t.Errorf("unexpected decoded json of flags %v: %v", decodedPermissions, err)
+	}
+
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookProxy := newFixture(t, addressBookImpl).proxy
+
+	var dbusErr dbus.Error
+	err = addressBookProxy.SetContacts([]AddressBook.Contact{{Idx: 1, Name: "Name1", Type: AddressBook.ContactType(7)}})
+	if !errors.As(err, &dbusErr) || dbusErr.Name != goqface.InvalidArgsErrorName {
+		t.Errorf("out of range contact type not rejected with %v: %v", goqface.InvalidArgsErrorName, err)
+	}
+	if len(addressBookImpl.Contacts()) != 0 {
+		t.Errorf("out of range contact type applied %v", addressBookImpl.Contacts())
+	}
+	contacts := []AddressBook.Contact{{Idx: 1, Name: "Name1", Type: AddressBook.Colleague}}
+	if err := addressBookProxy.SetContacts(contacts); err != nil {
+		t.Errorf("valid contact type rejected %v", err)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	nested := AddressBook.Nested{Title: "Title", ListOfContact: AddressBook.Contact{Idx: 1, Name: "Name1", Number: "123", Type: AddressBook.Colleague}}
+	data, err := json.Marshal(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"title":"Title","contact":{"idx":1,"name":"Name1","number":"123","type":"Colleague"}}`
+	if string(data) != expected {
+		t.Errorf("unexpected json! have %s want %s", data, expected)
+	}
+	var decoded AddressBook.Nested
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != nested {
+		t.Errorf("json round trip mismatch! have %v want %v: %v", decoded, nested, err)
+	}
+
+	var contact AddressBook.Contact
+	if err := json.Unmarshal([]byte(`{"name":"Name2"}`), &contact); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (AddressBook.Contact{Idx: -1, Name: "Name2", Type: AddressBook.Family}); contact != expected {
+		t.Errorf("missing fields not defaulted! have %v want %v", contact, expected)
+	}
+	if err := json.Unmarshal([]byte(`{"type":"Enemy"}`), &contact); err == nil {
+		t.Errorf("unknown contact type accepted")
+	}
+
+	// a YAML decoder fills the value passed to unmarshal by the fields present in the document
+	contact = AddressBook.Contact{}
+	err = contact.UnmarshalYAML(func(value interface{}) error {
+		return json.Unmarshal([]byte(`{"name":"Name3"}`), value)
+	})
+	if expected := (AddressBook.Contact{Idx: -1, Name: "Name3", Type: AddressBook.Family}); err != nil || contact != expected {
+		t.Errorf("missing fields not defaulted by UnmarshalYAML! have %v want %v: %v", contact, expected, err)
+	}
+}
+
+func TestCloneEqual(t *testing.T) {
+	nested := AddressBook.Nested{Title: "Title", ListOfContact: AddressBook.Contact{Idx: 1, Name: "Name1"}}
+	if !nested.Equal(nested.Clone()) || nested.Equal(AddressBook.Nested{Title: "Title"}) {
+		t.Errorf("unexpected equality of %v", nested)
+	}
+
+	contacts := []AddressBook.Contact{{Idx: 1, Name: "Name1"}}
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookImpl.SetContacts(contacts)
+	contacts[0].Name = "Modified"
+	if addressBookImpl.Contacts()[0].Name != "Name1" {
+		t.Errorf("contacts shared with caller of SetContacts")
+	}
+	contactsByType := map[int][]AddressBook.Contact{1: {{Idx: 1}}}
+	addressBookImpl.SetContactsByType(contactsByType)
+	contactsByType[1][0].Idx = 2
+	if addressBookImpl.ContactsByType()[1][0].Idx != 1 {
+		t.Errorf("contactsByType shared with caller of SetContactsByType")
+	}
+	addressBookImpl.Contacts()[0].Name = "Modified"
+	addressBookImpl.ContactsByType()[1][0].Idx = 2
+	if addressBookImpl.Contacts()[0].Name != "Name1" || addressBookImpl.ContactsByType()[1][0].Idx != 1 {
+		t.Errorf("properties shared with caller of getters")
+	}
+}
+
+func TestObserversModifying(t *testing.T) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookProxy := newFixture(t, addressBookImpl).proxy
+
+	baseReceived := make(chan struct{}, 1)
+	unsubscribe := addressBookImpl.OnContactsChanged(func(contacts []AddressBook.Contact) {
+		contacts[0].Name = "Modified"
+		baseReceived <- struct{}{}
+	})
+	addressBookImpl.SetContacts([]AddressBook.Contact{{Idx: 1, Name: "Name1"}})
+	select {
+	case <-baseReceived:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for contacts of base")
+	}
+	if name := addressBookImpl.Contacts()[0].Name; name != "Name1" {
+		t.Errorf("contacts of base modified by observer to %v", name)
+	}
+	unsubscribe()
+
+	proxyReceived := make(chan struct{}, 1)
+	defer addressBookProxy.OnContactsChanged(func(contacts []AddressBook.Contact) {
+		if contacts[0].Idx != 2 {
+			return
+		}
+		contacts[0].Name = "Modified"
+		proxyReceived <- struct{}{}
+	})()
+	addressBookImpl.SetContacts([]AddressBook.Contact{{Idx: 2, Name: "Name2"}})
+	select {
+	case <-proxyReceived:
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for contacts of proxy")
+	}
+	if name := addressBookProxy.Contacts()[0].Name; name != "Name2" {
+		t.Errorf("contacts of proxy modified by observer to %v", name)
+	}
+}
+
+func benchmarkContacts(n int) []AddressBook.Contact {
+	contacts := make([]AddressBook.Contact, n)
+	for i := range contacts {
+		contacts[i] = AddressBook.Contact{Idx: i, Name: "Name" + strconv.Itoa(i), Number: "12345" + strconv.Itoa(i), Type: AddressBook.Family}
+	}
+	return contacts
+}
+
+func BenchmarkEqualContactsReflect(b *testing.B) {
+	contacts, other := benchmarkContacts(1000), benchmarkContacts(1000)
+	for i := 0; i < b.N; i++ {
+		if !reflect.DeepEqual(contacts, other) {
+			b.Fatal("contacts differ")
+		}
+	}
+}
+
+func BenchmarkEqualContactsGenerated(b *testing.B) {
+	contacts, other := benchmarkContacts(1000), benchmarkContacts(1000)
+	for i := 0; i < b.N; i++ {
+		if !goqface.EqualSlices(contacts, other, AddressBook.Contact.Equal) {
+			b.Fatal("contacts differ")
+		}
+	}
+}
+
+func BenchmarkSetContactsUnchanged(b *testing.B) {
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookImpl.SetContacts(benchmarkContacts(1000))
+	contacts := benchmarkContacts(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		addressBookImpl.SetContacts(contacts)
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	if err := (AddressBook.Contact{Idx: 1, Name: "Name1", Number: "+49 123"}).Validate(); err != nil {
+		t.Errorf("valid contact rejected %v", err)
+	}
+	invalidContacts := []AddressBook.Contact{
+		{Idx: -2},
+		{Idx: 1, Name: strings.Repeat("x", 65)},
+		{Idx: 1, Number: "123-456"},
+		{Idx: 1, Type: AddressBook.ContactType(7)},
+	}
+	for _, contact := range invalidContacts {
+		if err := contact.Validate(); !errors.Is(err, goqface.ErrInvalidArgs) {
+			t.Errorf("invalid contact %v accepted: %v", contact, err)
+		}
+	}
+	if err := (AddressBook.Nested{ListOfContact: AddressBook.Contact{Idx: -2}}).Validate(); !errors.Is(err, goqface.ErrInvalidArgs) {
+		t.Errorf("invalid nested contact accepted: %v", err)
+	}
+
+	addressBookImpl := &AddressBookImpl{AddressBook.NewAddressBookBase()}
+	addressBookProxy := newFixture(t, addressBookImpl).proxy
+
+	var dbusErr dbus.Error
+	expectInvalidArgs := func(what string, err error) {
+		t.Helper()
+		if !errors.As(err, &dbusErr) || dbusErr.Name != goqface.InvalidArgsErrorName {
+			t.Errorf("%s not rejected with %v: %v", what, goqface.InvalidArgsErrorName, err)
+		}
+	}
+	expectInvalidArgs("negative debt", addressBookProxy.SetDebt(-1))
+	expectInvalidArgs("long name", addressBookProxy.SetCurrentContact(AddressBook.Contact{Idx: 1, Name: strings.Repeat("x", 65)}))
+	expectInvalidArgs("invalid number", addressBookProxy.SetContacts([]AddressBook.Contact{{Idx: 1, Number: "123-456"}}))
+	expectInvalidArgs("negative contactId", addressBookProxy.SelectContact(-5))
+	_, _, err := addressBookProxy.FindContact("")
+	expectInvalidArgs("empty name", err)
+	if addressBookImpl.Debt() != 0 || len(addressBookImpl.Contacts()) != 0 || addressBookImpl.CurrentContact().Idx != -1 {
+		t.Errorf("invalid values applied")
+	}
+	if err := addressBookProxy.SetDebt(100); err != nil {
+		t.Errorf("valid debt rejected %v", err)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	f := newFixture(t, &AddressBookImpl{AddressBook.NewAddressBookBase()})
+	if f.proxy.Version() != AddressBook.ModuleVersion || AddressBook.ModuleVersion != "1.0" {
+		t.Errorf("version of remote object mismatch! have %v want %v", f.proxy.Version(), AddressBook.ModuleVersion)
+	}
+
+	incompatiblePath := f.adapter.ObjectPath() + "/Incompatible"
+	incompatibleAdapter := AddressBook.NewAddressBookAdapter(f.server, &AddressBookImpl{AddressBook.NewAddressBookBase()}, goqface.WithObjectPath(incompatiblePath))
+	incompatibleAdapter.PropsSpec[goqface.VersionProperty].Get = func() interface{} { return "2.0" }
+	if err := incompatibleAdapter.Export(); err != nil {
+		t.Fatal(err)
+	}
+	defer incompatibleAdapter.Close()
+
+	incompatibleProxy := AddressBook.NewAddressBookProxy(f.client, goqface.WithObjectPath(incompatiblePath), goqface.WithServiceName(f.server.Names()[0]))
+	incompatibleProxy.ConnectToRemoteObject()
+	if incompatibleProxy.Version() != "2.0" {
+		t.Errorf("version of remote object mismatch! have %v want %v", incompatibleProxy.Version(), "2.0")
+	}
+	if incompatibleProxy.Ready() {
+		t.Errorf("proxy of remote object of another major version reported ready")
+	}
+
+	if err := goqface.CheckVersion("1.0", "1.3"); err != nil {
+		t.Errorf("minor versions reported incompatible: %v", err)
+	}
+	if err := goqface.CheckVersion("1.0", "2.0"); !errors.Is(err, goqface.ErrIncompatibleVersion) {
+		t.Errorf("major versions not reported incompatible: %v", err)
 	}
 }
@@ -0,0 +1,150 @@
+package compat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type module struct {
+	Name               string `json:"name"`
+	OldVersion         string `json:"oldVersion"`
+	NewVersion         string `json:"newVersion"`
+	MajorVersionBumped bool   `json:"majorVersionBumped"`
+}
+
+type change struct {
+	Module   string `json:"module"`
+	Symbol   string `json:"symbol"`
+	Change   string `json:"change"`
+	Breaking bool   `json:"breaking"`
+	Message  string `json:"message"`
+}
+
+// report is the json report of codegen.py compat
+type report struct {
+	Compatible bool     `json:"compatible"`
+	Modules    []module `json:"modules"`
+	Changes    []change `json:"changes"`
+}
+
+// expectedChange identifies a change by the name of its symbol along with its kind
+type expectedChange struct {
+	name     string
+	change   string
+	breaking bool
+}
+
+// changesFromOld are the changes of new and major compared to old
+var changesFromOld = []expectedChange{
+	{"reset", "removed", true},
+	{"lookup", "renamed", false},
+	{"clear", "added", false},
+	{"count", "retyped", true},
+	{"owner", "readonly", true},
+	{"balance", "emits", true},
+	{"active", "added", false},
+	{"Entry", "fields reordered", true},
+	{"Kind", "member removed", true},
+	{"Kind", "member value", true},
+}
+
+// compat runs the compat subcommand comparing the revisions of Compat.qface in the folders old and new,
+// it returns the output and exit status
+func compat(t *testing.T, old, new string, args ...string) ([]byte, int) {
+	t.Helper()
+	if err := exec.Command("python3", "-c", "import qface").Run(); err != nil {
+		t.Skipf("qface required by the generator not installed: %v", err)
+	}
+	args = append([]string{"../../generator/codegen.py", "compat", "--old", old, "--new", new, "--dependency", "dependency"}, args...)
+	cmd := exec.Command("python3", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && len(output) > 0 {
+		return output, exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("compat failed to run: %v\n%s", err, stderr.String())
+	}
+	return output, 0
+}
+
+// compatReport runs compat with a json report
+func compatReport(t *testing.T, old, new string) (report, int) {
+	t.Helper()
+	output, status := compat(t, old, new, "--format", "json")
+	var r report
+	if err := json.Unmarshal(output, &r); err != nil {
+		t.Fatalf("invalid report %s: %v", output, err)
+	}
+	return r, status
+}
+
+func checkChanges(t *testing.T, changes []change, expected []expectedChange) {
+	t.Helper()
+	if len(changes) != len(expected) {
+		t.Errorf("unexpected number of changes! have %d want %d: %v", len(changes), len(expected), changes)
+	}
+	for _, want := range expected {
+		found := false
+		for _, have := range changes {
+			if strings.HasSuffix(have.Symbol, want.name) && have.Change == want.change && have.Breaking == want.breaking {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("change %v of %v not reported in %v", want.change, want.name, changes)
+		}
+	}
+}
+
+func TestBreakingChanges(t *testing.T) {
+	r, status := compatReport(t, "old", "new")
+	if status != 1 || r.Compatible {
+		t.Errorf("breaking changes reported compatible with status %d", status)
+	}
+	// the module passed by --dependency is not compared
+	if want := []module{{Name: "Tests.Compat", OldVersion: "1.0", NewVersion: "1.1"}}; !reflect.DeepEqual(r.Modules, want) {
+		t.Errorf("modules mismatch! have %v want %v", r.Modules, want)
+	}
+	checkChanges(t, r.Changes, changesFromOld)
+}
+
+func TestMajorVersionBump(t *testing.T) {
+	r, status := compatReport(t, "old", "major")
+	if status != 0 || !r.Compatible {
+		t.Errorf("breaking changes along with a major version bump reported incompatible with status %d", status)
+	}
+	if want := []module{{Name: "Tests.Compat", OldVersion: "1.0", NewVersion: "2.0", MajorVersionBumped: true}}; !reflect.DeepEqual(r.Modules, want) {
+		t.Errorf("modules mismatch! have %v want %v", r.Modules, want)
+	}
+	checkChanges(t, r.Changes, changesFromOld)
+}
+
+func TestUnchanged(t *testing.T) {
+	r, status := compatReport(t, "old", "old")
+	if status != 0 || !r.Compatible || len(r.Changes) != 0 {
+		t.Errorf("unchanged module reported changes %v with status %d", r.Changes, status)
+	}
+}
+
+func TestTextReport(t *testing.T) {
+	output, status := compat(t, "old", "new")
+	if status != 1 {
+		t.Errorf("unexpected status %d", status)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != len(changesFromOld)+1 || !strings.HasPrefix(lines[len(lines)-1], "7 breaking changes") {
+		t.Errorf("unexpected text report\n%s", output)
+	}
+
+	output, status = compat(t, "old", "major")
+	if status != 0 || !strings.Contains(string(output), "breaking (major version increased): ") {
+		t.Errorf("unexpected text report with status %d\n%s", status, output)
+	}
+}